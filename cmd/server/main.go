@@ -4,6 +4,8 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"go-crawler/internal/crawl"
 	httppkg "go-crawler/internal/http"
@@ -11,9 +13,17 @@ import (
 	"go-crawler/internal/search"
 	"go-crawler/internal/service"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 )
 
+// defaultOrphanStaleAfter is how old another instance's heartbeat must be before its
+// RUNNING jobs are considered orphaned. Override with ORPHAN_STALE_AFTER (e.g. "5m").
+const defaultOrphanStaleAfter = 4 * service.HeartbeatInterval
+
+// defaultMaxConcurrentJobs is how many crawls run at once. Override with MAX_CONCURRENT_JOBS.
+const defaultMaxConcurrentJobs = 2
+
 func main() {
 	_ = godotenv.Load() // load .env if present; ignore error so prod can rely on real env
 	ctx := context.Background()
@@ -29,17 +39,53 @@ func main() {
 	}
 	defer repo.Close(ctx)
 	index := search.NewIndex()
-	pages, err := repo.ListPagesForIndex(ctx)
+	pageRepositoryWriter := service.NewIndexingWriter(repo, index)
+	pages, err := repo.ListPagesForIndex(ctx, pageRepositoryWriter.MainContentWeight)
 	if err != nil {
 		log.Fatalf("Failed to list pages for index: %v", err)
 	}
 	index.BuildFromDocuments(pages)
 	log.Println("Index built with", len(pages), "documents")
-	pageRepositoryWriter := service.NewIndexingWriter(repo, index)
-	engine := crawl.NewEngine(10, repo, pageRepositoryWriter)
-	svc := service.NewCrawlService(repo, repo, engine)
+	engine := crawl.NewEngine(10, repo, pageRepositoryWriter, repo, repo, repo, repo)
+
+	instanceID := os.Getenv("INSTANCE_ID")
+	if instanceID == "" {
+		instanceID = uuid.New().String()
+	}
+	log.Println("Instance ID:", instanceID)
+	maxConcurrent := defaultMaxConcurrentJobs
+	if v := os.Getenv("MAX_CONCURRENT_JOBS"); v != "" {
+		if maxConcurrent, err = strconv.Atoi(v); err != nil || maxConcurrent < 1 {
+			log.Fatalf("MAX_CONCURRENT_JOBS must be a positive integer, got %q", v)
+		}
+	}
+	svc := service.NewCrawlService(repo, repo, engine, instanceID, maxConcurrent)
+
+	// ORPHAN_POLICY says what to do with RUNNING jobs whose instance stopped:
+	// "requeue" (default) or "fail".
+	policy := service.OrphanPolicy(os.Getenv("ORPHAN_POLICY"))
+	if policy == "" {
+		policy = service.OrphanRequeue
+	}
+	if policy != service.OrphanRequeue && policy != service.OrphanFail {
+		log.Fatalf("ORPHAN_POLICY must be %q or %q, got %q", service.OrphanRequeue, service.OrphanFail, policy)
+	}
+	staleAfter := defaultOrphanStaleAfter
+	if v := os.Getenv("ORPHAN_STALE_AFTER"); v != "" {
+		if staleAfter, err = time.ParseDuration(v); err != nil || staleAfter <= 0 {
+			log.Fatalf("ORPHAN_STALE_AFTER must be a positive duration, got %q", v)
+		}
+	}
+	reconciler := service.NewReconciler(repo, instanceID, policy, staleAfter)
+	requeued, failed, err := reconciler.Reconcile(ctx, true)
+	if err != nil {
+		log.Fatalf("Failed to reconcile jobs: %v", err)
+	}
+	log.Printf("Reconciled jobs: %d orphaned requeued, %d orphaned failed", requeued, failed)
+	go reconciler.Run(ctx)
+	svc.StartDispatcher(ctx)
 
-	httpServer := httppkg.NewServer(svc, index, repo)
+	httpServer := httppkg.NewServer(svc, index, repo, pageRepositoryWriter.MainContentWeight)
 	log.Println("Starting server on port 8080")
 	log.Fatal(httpServer.Start(":8080"))
 }
@@ -0,0 +1,31 @@
+package crawl
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// decodeBody transcodes body to UTF-8 and returns it with the name of the encoding it
+// was decoded from. The encoding comes from, in order: a byte order mark, the
+// Content-Type charset, a <meta charset> or http-equiv declaration in the first 1024
+// bytes, then sniffing: valid UTF-8 is taken as UTF-8 and anything else as
+// windows-1252, the WHATWG default (which ISO-8859-1 labels also map to).
+// If decoding fails the body is returned unchanged.
+func decodeBody(body []byte, contentType string) ([]byte, string) {
+	enc, name, certain := charset.DetermineEncoding(body, contentType)
+	// Without a BOM or header, DetermineEncoding only sniffs the first 1024 bytes, so a
+	// page that is ASCII up to there falls back to windows-1252. Check the whole body.
+	if !certain && name == "windows-1252" && utf8.Valid(body) {
+		name = "utf-8"
+	}
+	if name == "utf-8" {
+		return bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), name
+	}
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, ""
+	}
+	return bytes.TrimPrefix(decoded, []byte("\xef\xbb\xbf")), name
+}
@@ -0,0 +1,43 @@
+package crawl
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDecodeBody(t *testing.T) {
+	asciiPad := strings.Repeat("a", 1100)
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		want        string
+		wantCharset string
+	}{
+		{"utf-8 bom", "\xef\xbb\xbf<p>café</p>", "text/html", "<p>café</p>", "utf-8"},
+		{"bom beats header", "\xef\xbb\xbf<p>café</p>", "text/html; charset=windows-1252", "<p>café</p>", "utf-8"},
+		{"utf-16le bom", "\xff\xfe<\x00p\x00>\x00h\x00i\x00", "text/html", "<p>hi", "utf-16le"},
+		{"header charset", "<p>caf\xe9</p>", "text/html; charset=windows-1252", "<p>café</p>", "windows-1252"},
+		{"latin-1 label is windows-1252", "<p>\x80 caf\xe9</p>", "text/html; charset=ISO-8859-1", "<p>€ café</p>", "windows-1252"},
+		{"meta charset", `<meta charset="windows-1252"><p>caf` + "\xe9", "text/html", `<meta charset="windows-1252"><p>café`, "windows-1252"},
+		{
+			"http-equiv shift_jis",
+			`<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS"><p>` + "\x93\xfa\x96\x7b",
+			"text/html",
+			`<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS"><p>日本`,
+			"shift_jis",
+		},
+		{"header beats meta", `<meta charset="utf-8"><p>caf` + "\xe9", "text/html; charset=windows-1252", `<meta charset="utf-8"><p>café`, "windows-1252"},
+		{"utf-8 header beats shift_jis meta", `<meta charset="shift_jis"><p>日本`, "text/html; charset=utf-8", `<meta charset="shift_jis"><p>日本`, "utf-8"},
+		{"unknown header label", "<p>café</p>", "text/html; charset=bogus", "<p>café</p>", "utf-8"},
+		{"fallback utf-8", "<p>café</p>", "", "<p>café</p>", "utf-8"},
+		{"fallback utf-8 past the sniffed prefix", asciiPad + "é", "text/html", asciiPad + "é", "utf-8"},
+		{"fallback windows-1252", "<p>caf\xe9</p>", "", "<p>café</p>", "windows-1252"},
+	}
+	for _, tt := range tests {
+		got, charset := decodeBody([]byte(tt.body), tt.contentType)
+		if string(got) != tt.want || charset != tt.wantCharset {
+			t.Errorf("%s: decodeBody = %q, %q; want %q, %q", tt.name, got, charset, tt.want, tt.wantCharset)
+		}
+	}
+}
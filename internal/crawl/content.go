@@ -0,0 +1,115 @@
+package crawl
+
+import (
+	"fmt"
+	"go-crawler/internal/model"
+	"net/http"
+	"strings"
+)
+
+// Defaults for a job's content policy.
+const defaultMaxBodyBytes = 10 << 20 // 10 MiB
+
+var defaultContentTypes = []string{"text/html", "application/xhtml+xml"}
+
+// sniffLen is how many leading body bytes are inspected, as in http.DetectContentType.
+const sniffLen = 512
+
+// contentPolicy decides which responses are worth reading and parsing.
+type contentPolicy struct {
+	types    []string // lowercased media types; "type/*" and "*/*" are wildcards
+	maxBytes int64
+	head     bool // probe with HEAD before GET
+}
+
+func newContentPolicy(input model.CrawlInput) *contentPolicy {
+	p := &contentPolicy{
+		maxBytes: input.MaxBodyBytes,
+		head:     input.HeadRequest,
+	}
+	if p.maxBytes <= 0 {
+		p.maxBytes = defaultMaxBodyBytes
+	}
+	types := input.AllowedContentTypes
+	if len(types) == 0 {
+		types = defaultContentTypes
+	}
+	for _, t := range types {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			p.types = append(p.types, t)
+		}
+	}
+	return p
+}
+
+// allows reports whether media type mt (without parameters) is one of the policy's types.
+func (p *contentPolicy) allows(mt string) bool {
+	mt = strings.ToLower(mt)
+	major, _, _ := strings.Cut(mt, "/")
+	for _, t := range p.types {
+		if t == mt || t == "*/*" || t == major+"/*" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDeclared applies the policy to a response's headers, before any body is read.
+// A missing or generic Content-Type passes; the body is sniffed instead.
+func (p *contentPolicy) checkDeclared(header http.Header, contentLength int64) *fetchError {
+	if contentLength > p.maxBytes {
+		return tooLarge(p.maxBytes)
+	}
+	declared := mediaType(header.Get("Content-Type"))
+	if declared == "" || declared == "application/octet-stream" {
+		return nil
+	}
+	if !p.allows(declared) {
+		return disallowedType(declared)
+	}
+	return nil
+}
+
+// checkSniffed applies the policy to the first bytes of a body and returns the sniffed
+// media type. The sniffed type must be allowed when the server declared none, and a
+// binary format the policy does not allow is rejected even when mislabelled as, say,
+// text/html.
+func (p *contentPolicy) checkSniffed(header http.Header, head []byte) (string, *fetchError) {
+	sniffed := mediaType(http.DetectContentType(head))
+	declared := mediaType(header.Get("Content-Type"))
+	if p.allows(sniffed) {
+		return sniffed, nil
+	}
+	if declared == "" || declared == "application/octet-stream" || !strings.HasPrefix(sniffed, "text/") {
+		return sniffed, disallowedType(sniffed)
+	}
+	return sniffed, nil
+}
+
+func tooLarge(maxBytes int64) *fetchError {
+	return &fetchError{
+		err:   &bodyLimitError{maxBytes: maxBytes},
+		class: model.FetchErrorTooLarge,
+		skip:  model.SkipReasonTooLarge,
+	}
+}
+
+func disallowedType(mt string) *fetchError {
+	return &fetchError{
+		err:   &contentTypeError{mediaType: mt},
+		class: model.FetchErrorContentType,
+		skip:  model.SkipReasonContentType,
+	}
+}
+
+type bodyLimitError struct{ maxBytes int64 }
+
+func (e *bodyLimitError) Error() string {
+	return fmt.Sprintf("body exceeds %d bytes", e.maxBytes)
+}
+
+type contentTypeError struct{ mediaType string }
+
+func (e *contentTypeError) Error() string {
+	return fmt.Sprintf("content type %q not allowed", e.mediaType)
+}
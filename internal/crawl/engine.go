@@ -1,14 +1,20 @@
 package crawl
 
 import (
+	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"go-crawler/internal/model"
+	"go-crawler/internal/robots"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
@@ -24,184 +30,562 @@ type PageWriter interface {
 	CreatePage(ctx context.Context, page *model.Page) error
 }
 
+// SkipRecorder is used by the engine to record URLs it decided not to fetch, with the reason.
+// Implemented by the repository.
+type SkipRecorder interface {
+	RecordSkip(ctx context.Context, jobID string, url string, reason model.SkipReason) error
+}
+
+// FetchRecorder is used by the engine to log every HTTP request it makes and its
+// outcome. Implemented by the repository.
+type FetchRecorder interface {
+	RecordFetch(ctx context.Context, fetch *model.Fetch) error
+}
+
+// LinkRecorder stores the edges of each job's link graph: the links on a fetched page
+// that the job accepted. Implemented by the repository.
+type LinkRecorder interface {
+	RecordLinks(ctx context.Context, jobID string, links []*model.LinkEdge) error
+}
+
+// Frontier persists each job's discovered URLs and their progress so an interrupted
+// crawl can resume where it stopped. Implemented by the repository.
+type Frontier interface {
+	AddFrontierTask(ctx context.Context, jobID string, task *model.URLTask) error
+	SetFrontierState(ctx context.Context, jobID string, url string, state model.FrontierState) error
+	RetryFrontierTask(ctx context.Context, jobID string, task *model.URLTask) error
+	LoadFrontier(ctx context.Context, jobID string) ([]*model.FrontierEntry, error)
+}
+
+// robotsUserAgent is the product token matched against robots.txt User-agent lines.
+const robotsUserAgent = "go-crawler"
+
+// Engine holds resources shared by all jobs. Per-job state lives in crawlSession,
+// so one Engine can run many jobs concurrently.
 type Engine struct {
 	workerCount  int
 	client       *http.Client
-	allowedHost  string
+	robots       *robots.Checker
 	pagesLimiter PagesCrawledLimiter
 	pageWriter   PageWriter
+	skipRecorder SkipRecorder
+	frontier     Frontier
+	fetchLog     FetchRecorder
+	linkGraph    LinkRecorder
 }
 
-func NewEngine(workerCount int, pagesLimiter PagesCrawledLimiter, pageWriter PageWriter) *Engine {
-	return &Engine{
-		workerCount: workerCount,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+func NewEngine(workerCount int, pagesLimiter PagesCrawledLimiter, pageWriter PageWriter, skipRecorder SkipRecorder, frontier Frontier, fetchLog FetchRecorder, linkGraph LinkRecorder) *Engine {
+	client := &http.Client{
+		Timeout:       10 * time.Second,
+		CheckRedirect: checkRedirect,
+	}
+	// robots.txt is fetched with a client of its own: its redirects are not page
+	// redirects, so they must not be vetted against the job's rules or traced into a
+	// page's RedirectChain. Vetting would also deadlock, as it checks robots.txt for
+	// the very origin being fetched.
+	robotsClient := &http.Client{Timeout: 10 * time.Second}
+	e := &Engine{
+		workerCount:  workerCount,
+		client:       client,
+		robots:       robots.NewChecker(robotsClient, robotsUserAgent),
 		pagesLimiter: pagesLimiter,
 		pageWriter:   pageWriter,
+		skipRecorder: skipRecorder,
+		frontier:     frontier,
+		fetchLog:     fetchLog,
+		linkGraph:    linkGraph,
 	}
+	e.robots.OnFetch = e.recordRobotsFetch
+	return e
 }
 
-func (e *Engine) Start(ctx context.Context, job *model.CrawlJob) error {
-	urlQueue := make(chan *model.URLTask, 1000)
-	var wg sync.WaitGroup
-
-	visitedURL := NewVisitedURLStore()
+// jobIDKey carries the job ID in a crawl's context, so robots.txt requests made on
+// the job's behalf are logged with it.
+type jobIDKey struct{}
 
-	// Use an atomic counter to track active tasks (queued + in-progress).
-	// Bump up on enqueue, down on finish. When it hits zero, close the queue.
-	// Only one goroutine should close.
-	var activeCount atomic.Int32
-	activeCount.Store(1) // Seed task we're about to enqueue
+// Validate reports whether a job with input could be started, so a bad start URL,
+// scope, URL rule or extraction rule is rejected when the job is submitted rather
+// than when it runs.
+func (e *Engine) Validate(input model.CrawlInput) error {
+	_, err := newCrawlSession(&model.CrawlJob{Input: input}, e.frontier, nil)
+	return err
+}
 
-	seedUrl, err := url.Parse(job.Input.StartURL)
+// Start crawls job until its frontier is exhausted or ctx is cancelled. If the job
+// already has a persisted frontier (e.g. after a restart), the crawl resumes from it.
+func (e *Engine) Start(ctx context.Context, job *model.CrawlJob) error {
+	ctx = context.WithValue(ctx, jobIDKey{}, job.ID)
+	// Workers read from the per-host scheduler, which drains urlQueue and enforces
+	// RequestDelayMs, MaxConcurrentPerHost and robots.txt Crawl-delay.
+	var hostDelay func(string) time.Duration
+	if !job.Input.IgnoreRobots {
+		hostDelay = e.robots.CachedCrawlDelay
+	}
+	sess, err := newCrawlSession(job, e.frontier, hostDelay)
 	if err != nil {
-		return fmt.Errorf("invalid start URL: %w", err)
+		return err
 	}
-	e.allowedHost = seedUrl.Host
-	// Seed initial URL
-	urlQueue <- &model.URLTask{
-		URL:   job.Input.StartURL,
-		Depth: 0,
+	go sess.sched.run(ctx)
+	if err := sess.start(ctx); err != nil {
+		return err
 	}
 
+	var wg sync.WaitGroup
 	for i := 0; i < e.workerCount; i++ {
 		wg.Add(1)
-		go e.worker(ctx, &wg, urlQueue, visitedURL, job, &activeCount)
+		go e.worker(ctx, &wg, sess)
 	}
 	wg.Wait()
-	// Channel is closed by the worker that decrements activeCount to 0
-	return nil
+	// The queue is closed by the worker that decrements activeCount to 0; the scheduler
+	// then closes its output and the workers exit. On cancellation workers keep draining
+	// the queue (skipping each task) until it closes.
+	return ctx.Err()
 }
 
-func (e *Engine) worker(ctx context.Context, wg *sync.WaitGroup, urlQueue chan *model.URLTask, visitedURL *VisitedURLStore, job *model.CrawlJob, activeCount *atomic.Int32) {
+func (e *Engine) worker(ctx context.Context, wg *sync.WaitGroup, sess *crawlSession) {
 	defer wg.Done()
-	for task := range urlQueue {
-		e.processTask(ctx, urlQueue, visitedURL, job, task, activeCount)
+	for task := range sess.sched.out {
+		state := e.processTask(ctx, sess, task)
+		sess.done(ctx, task, state)
 	}
 }
 
-// processTask handles one URL. When done, decrements activeCount. If it
-// enqueues child tasks, it increments activeCount for each.
-func (e *Engine) processTask(ctx context.Context, urlQueue chan *model.URLTask, visitedURL *VisitedURLStore, job *model.CrawlJob, task *model.URLTask, activeCount *atomic.Int32) {
-	defer func() {
-		// Decrement: we're done with this task (processed or skipped).
-		// The worker that brings activeCount to 0 closes the channel.
-		if activeCount.Add(-1) == 0 {
-			close(urlQueue)
-		}
-	}()
-
+// processTask handles one URL and returns the state to record for it in the frontier.
+// Tasks are marked visited when enqueued, so a URL reaches here once, plus once per
+// retry. The worker calls sess.done when it returns.
+func (e *Engine) processTask(ctx context.Context, sess *crawlSession, task *model.URLTask) (state model.FrontierState) {
+	job := sess.job
 	if ctx.Err() != nil {
-		return
+		return model.FrontierQueued
 	}
-	// Skip if already visited (dedupe)
-	visited := visitedURL.MarkIfNotVisited(task.URL)
-	if !visited {
-		fmt.Println("Skipping already visited URL:", task.URL)
-		return
+
+	// -------------------------ROBOTS.TXT --------------------------
+
+	if !job.Input.IgnoreRobots {
+		allowed, err := e.robots.Allowed(ctx, task.URL)
+		if err != nil {
+			fmt.Println("[crawl] Error checking robots.txt:", err)
+			return model.FrontierDone
+		}
+		if !allowed {
+			fmt.Println("[crawl] Disallowed by robots.txt:", task.URL)
+			e.recordSkip(ctx, job.ID, task.URL, model.SkipReasonRobots)
+			return model.FrontierDone
+		}
 	}
 
 	fmt.Println("Fetching:", task.URL)
 	// -------------------------HTTP FETCH --------------------------
 
-	req, err := http.NewRequestWithContext(ctx, "GET", task.URL, nil)
-	if err != nil {
-		fmt.Println("Error creating request:", err)
-		return
-	}
-	// Many sites (golang.org, go.dev, google.com) return 403 or redirect for default "Go-http-client/1.1"
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	// Every request is logged with how it ended, including the checks after the fetch.
+	rec := &model.Fetch{JobID: job.ID, URL: task.URL, Attempt: task.Attempt + 1}
+	defer e.recordFetch(ctx, rec)
 
-	resp, err := e.client.Do(req)
+	trace := &redirectTrace{check: func(ctx context.Context, target string) *redirectRejected {
+		return e.vetRedirect(ctx, sess, target)
+	}}
+	start := time.Now()
+	res, err := e.fetch(withRedirectTrace(ctx, trace), rec, sess.content)
+	duration := time.Since(start)
 	if err != nil {
-		fmt.Println("[crawl] Error fetching URL:", err)
-		return
+		rec.ErrorClass, rec.Error = errorClass(err), err.Error()
+		return e.fetchFailed(ctx, sess, task, err)
 	}
-	defer resp.Body.Close()
+	body := res.body
+	fmt.Println("Fetched:", task.URL, "with body length:", len(body))
 
-	fmt.Println("[crawl] Response status:", resp.StatusCode, "for", task.URL)
-	//only continue if the response is OK
-	if resp.StatusCode != http.StatusOK {
-		fmt.Println("[crawl] Skipping: not OK", resp.StatusCode)
-		return
-	}
+	// -------------------------REDIRECT TARGET --------------------------
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println("Error reading response body:", err)
-		return
+	// The page is stored under the URL that served it. Redirect hops were already
+	// vetted against scope, URL rules and robots.txt by vetRedirect.
+	pageURL := task.URL
+	if final, err := sess.norm.Normalize(res.finalURL); err == nil && final != task.URL {
+		if !sess.claimRedirect(ctx, task, final) {
+			fmt.Println("[crawl] Redirected to already visited URL:", task.URL, "->", final)
+			rec.ErrorClass = model.FetchErrorDuplicate
+			return model.FrontierDone
+		}
+		pageURL = final
+		// The redirect target was added to the frontier as QUEUED; it ends in the same
+		// state as task on every path, so a resumed crawl does not fetch it again.
+		defer func() {
+			if ctx.Err() == nil && state != model.FrontierQueued {
+				sess.setState(ctx, final, state)
+			}
+		}()
 	}
-	fmt.Println("Fetched:", task.URL, "with status:", resp.StatusCode, "and body length:", len(body))
 
-	// -------------------------MAX PAGES CHECK --------------------------
+	// -------------------------DECODE --------------------------
 
-	allowed, err := e.pagesLimiter.TryIncrementPagesCrawled(ctx, job.ID, job.Input.MaxPages)
-	if err != nil {
-		fmt.Println("[crawl] Error incrementing pages crawled:", err)
-		return
-	}
-	if !allowed {
-		fmt.Println("[crawl] Max pages reached:", job.Input.MaxPages)
-		return
-	}
+	// Parsing, storage and indexing all work on UTF-8; the hash and length stay those of
+	// the bytes received.
+	utf8Body, charset := decodeBody(body, res.contentType)
 
 	// -------------------------PARSE PAGE --------------------------
-	parsedPage, err := ParsePage(task.URL, body)
+	parsedPage, err := ParsePage(res.finalURL, utf8Body)
 	if err != nil {
 		fmt.Println("[crawl] Error parsing page:", err)
-		return
+		rec.ErrorClass, rec.Error = model.FetchErrorParse, err.Error()
+		return model.FrontierDone
 	}
 
+	// -------------------------ROBOTS DIRECTIVES --------------------------
+
+	// noindex and nofollow from meta tags and X-Robots-Tag, minus what the job ignores.
+	directives := sess.robots.apply(parsedPage.Robots.merge(xRobotsTag(res.header)))
+
+	// -------------------------MAX PAGES CHECK --------------------------
+
+	// MaxPages counts saved pages, so a noindex page does not use up a slot.
+	if !directives.NoIndex {
+		allowed, err := e.pagesLimiter.TryIncrementPagesCrawled(ctx, job.ID, job.Input.MaxPages)
+		if err != nil {
+			fmt.Println("[crawl] Error incrementing pages crawled:", err)
+			rec.ErrorClass, rec.Error = model.FetchErrorStore, err.Error()
+			return model.FrontierDone
+		}
+		if !allowed {
+			fmt.Println("[crawl] Max pages reached:", job.Input.MaxPages)
+			rec.ErrorClass = model.FetchErrorMaxPages
+			return model.FrontierDone
+		}
+	}
+
+	// -------------------------LINK FILTERING --------------------------
+
+	fmt.Println("Extracted links:", len(parsedPage.Links))
+	children, stats := sess.filterLinks(parsedPage.Links, directives.NoFollow)
+	canonical, followCanonical := sess.canonicalURL(parsedPage.Canonical)
+
 	// -------------------------SAVE PAGE --------------------------
-	page := &model.Page{
-		ID:          0, // repo assigns ID on persist
-		JobID:       job.ID,
-		URL:         task.URL,
-		Title:       parsedPage.Title,
-		Html:        string(body),
-		TextContent: parsedPage.TextContent,
-		FetchedAt:   time.Now(),
-	}
-	if err := e.pageWriter.CreatePage(ctx, page); err != nil {
-		fmt.Println("[crawl] Error saving page:", err)
-		return
+
+	if directives.NoIndex {
+		fmt.Println("[crawl] Not saving noindex page:", pageURL)
+		rec.ErrorClass = model.FetchErrorNoIndex
+	} else {
+		page := &model.Page{
+			ID:              0, // repo assigns ID on persist
+			JobID:           job.ID,
+			URL:             pageURL,
+			Title:           parsedPage.Title,
+			Html:            string(utf8Body),
+			TextContent:     parsedPage.TextContent,
+			MainContent:     parsedPage.MainContent,
+			FetchedAt:       time.Now(),
+			Stats:           stats,
+			StatusCode:      res.status,
+			FinalURL:        res.finalURL,
+			ContentType:     res.mediaType,
+			Charset:         charset,
+			Headers:         res.header,
+			ContentLength:   int64(len(body)),
+			FetchDurationMs: int(duration.Milliseconds()),
+			ContentHash:     contentHash(body),
+			RedirectChain:   trace.hops,
+			CanonicalURL:    canonical,
+			Metadata:        parsedPage.Metadata,
+			Extracted:       sess.extract.extract(parsedPage.doc),
+			Tables:          parsedPage.Tables,
+		}
+		if err := e.pageWriter.CreatePage(ctx, page); err != nil {
+			fmt.Println("[crawl] Error saving page:", err)
+			rec.ErrorClass, rec.Error = model.FetchErrorStore, err.Error()
+			return model.FrontierDone
+		}
+		fmt.Println("[crawl] Saved page:", page.URL, "job:", job.ID)
+	}
+	// -------------------------CANONICAL TARGET --------------------------
+
+	// The canonical page holds the same content, so it is not a level deeper. Like
+	// any other link, it is not followed from a nofollow page.
+	if job.Input.FollowCanonical && followCanonical && !directives.NoFollow && canonical != pageURL {
+		if sess.enqueue(ctx, &model.URLTask{
+			URL:            canonical,
+			Depth:          task.Depth,
+			DiscoveredFrom: pageURL,
+		}) {
+			fmt.Println("Enqueuing canonical:", canonical)
+		}
 	}
-	fmt.Println("[crawl] Saved page:", page.URL, "job:", job.ID)
 
 	// -------------------------MAX DEPTH CHECK --------------------------
 
 	if task.Depth >= job.Input.MaxDepth {
 		fmt.Println("Max depth reached:", task.Depth)
-		return
+		return model.FrontierDone
 	}
-	// -------------------------LINK EXTRACTION --------------------------
+	// -------------------------ENQUEUE LINKS --------------------------
 
-	fmt.Println("Extracted links:", parsedPage.Links)
-	for _, link := range parsedPage.Links {
+	// The link graph holds the links the crawl followed: each child is either enqueued
+	// here or already in the frontier.
+	edges := make([]*model.LinkEdge, 0, len(children))
+	for _, link := range children {
 		if ctx.Err() != nil {
-			return
+			break
+		}
+		if sess.enqueue(ctx, &model.URLTask{
+			URL:            link.URL,
+			Depth:          task.Depth + 1,
+			DiscoveredFrom: pageURL,
+		}) {
+			fmt.Println("Enqueuing:", link.URL)
 		}
+		edges = append(edges, &model.LinkEdge{
+			JobID:      job.ID,
+			SourceURL:  pageURL,
+			TargetURL:  link.URL,
+			AnchorText: link.Text,
+			Rel:        link.Rel,
+			Element:    link.Source,
+			Depth:      task.Depth + 1,
+		})
+	}
 
-		childUrl, err := url.Parse(link)
-		if err != nil {
-			fmt.Println("Error parsing link:", err)
-			continue
+	// -------------------------LINK GRAPH --------------------------
+
+	if err := e.linkGraph.RecordLinks(ctx, job.ID, edges); err != nil {
+		fmt.Println("[crawl] Error recording links:", err)
+	}
+	return model.FrontierDone
+}
+
+// fetchResult is what a fetch received. status is 0 and body empty when no
+// response arrived; body is only read for a 200.
+type fetchResult struct {
+	status      int
+	finalURL    string // URL of the last request, after redirects
+	header      http.Header
+	contentType string // raw Content-Type header
+	mediaType   string // declared media type, or the sniffed one if none was declared
+	body        []byte
+}
+
+// fetch GETs rec.URL and returns the body of a 200 response that passes policy. Any
+// other outcome is a *fetchError saying whether it is worth retrying. The result is
+// never nil. rec is filled in with the request's method, status, latency, size and
+// content type; the caller sets the error class and logs it. A HEAD probe is logged
+// here, unless it rejects the URL: then no GET is sent and rec describes the HEAD.
+func (e *Engine) fetch(ctx context.Context, rec *model.Fetch, policy *contentPolicy) (*fetchResult, error) {
+	rawURL := rec.URL
+	res := &fetchResult{}
+	if policy.head {
+		head := *rec
+		if err := e.probe(ctx, &head, policy); err != nil {
+			*rec = head
+			return res, err
 		}
-		if childUrl.Host != e.allowedHost {
-			fmt.Println("Skipping external link:", link)
-			continue
+		e.recordFetch(ctx, &head)
+	}
+	rec.Method = http.MethodGet
+	start := time.Now()
+	defer func() {
+		rec.LatencyMs = int(time.Since(start).Milliseconds())
+		rec.StatusCode, rec.ContentType, rec.Bytes = res.status, res.contentType, len(res.body)
+	}()
+
+	req, err := e.newRequest(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return res, &fetchError{err: err, class: model.FetchErrorNetwork}
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return res, transportError(err)
+	}
+	defer resp.Body.Close()
+	res.status = resp.StatusCode
+	res.finalURL = resp.Request.URL.String()
+	res.header = resp.Header
+	res.contentType = resp.Header.Get("Content-Type")
+
+	fmt.Println("[crawl] Response status:", resp.StatusCode, "for", rawURL)
+	//only continue if the response is OK
+	if resp.StatusCode != http.StatusOK {
+		return res, statusError(resp)
+	}
+	if fe := policy.checkDeclared(resp.Header, resp.ContentLength); fe != nil {
+		return res, fe
+	}
+
+	// Read at most one byte past the cap, so an oversized body is detected and cut off.
+	br := bufio.NewReaderSize(io.LimitReader(resp.Body, policy.maxBytes+1), sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return res, transportError(err)
+	}
+	sniffed, fe := policy.checkSniffed(resp.Header, head)
+	if fe != nil {
+		return res, fe
+	}
+	if declared := mediaType(res.contentType); declared == "" || declared == "application/octet-stream" {
+		res.mediaType = sniffed
+	} else {
+		res.mediaType = declared
+	}
+	if res.body, err = io.ReadAll(br); err != nil {
+		return res, transportError(err)
+	}
+	if int64(len(res.body)) > policy.maxBytes {
+		res.body = nil
+		return res, tooLarge(policy.maxBytes)
+	}
+	return res, nil
+}
+
+// probe sends a HEAD request for rec.URL and rejects it early if the response declares
+// a disallowed type or an oversized body. Servers that refuse or fail HEAD are not
+// held against the URL; the GET decides. rec is filled in like fetch does, with the
+// error class of a failure that is not returned.
+func (e *Engine) probe(ctx context.Context, rec *model.Fetch, policy *contentPolicy) error {
+	rec.Method = http.MethodHead
+	req, err := e.newRequest(ctx, http.MethodHead, rec.URL)
+	if err != nil {
+		return nil
+	}
+	start := time.Now()
+	resp, err := e.client.Do(req)
+	rec.LatencyMs = int(time.Since(start).Milliseconds())
+	if trace, ok := ctx.Value(redirectTraceKey{}).(*redirectTrace); ok {
+		trace.hops = nil // the GET records its own hops
+	}
+	if err != nil {
+		var rejected *redirectRejected
+		if errors.As(err, &rejected) {
+			return transportError(err)
 		}
-		visited := visitedURL.MarkIfNotVisited(link)
-		if visited {
-			fmt.Println("Enqueuing:", link)
-			activeCount.Add(1)
-			urlQueue <- &model.URLTask{
-				URL:   link,
-				Depth: task.Depth + 1,
-			}
+		rec.ErrorClass, rec.Error = classifyTransport(err), err.Error()
+		return nil
+	}
+	resp.Body.Close()
+	rec.StatusCode, rec.ContentType = resp.StatusCode, resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK {
+		fe := statusError(resp)
+		rec.ErrorClass, rec.Error = fe.class, fe.Error()
+		return nil
+	}
+	if fe := policy.checkDeclared(resp.Header, resp.ContentLength); fe != nil {
+		return fe
+	}
+	return nil
+}
+
+func (e *Engine) newRequest(ctx context.Context, method, rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Many sites (golang.org, go.dev, google.com) return 403 or redirect for default "Go-http-client/1.1"
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	return req, nil
+}
+
+// vetRedirect applies the job's scope, URL rules and robots.txt to a redirect target
+// before it is requested, so redirects cannot lead the crawl anywhere links could not.
+func (e *Engine) vetRedirect(ctx context.Context, sess *crawlSession, target string) *redirectRejected {
+	link, err := sess.norm.Normalize(target)
+	if err != nil {
+		return &redirectRejected{url: target, reason: model.SkipReasonOutOfScope}
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return &redirectRejected{url: link, reason: model.SkipReasonOutOfScope}
+	}
+	if reason, rule, ok := sess.checkLink(u); !ok {
+		return &redirectRejected{url: link, reason: reason, rule: rule}
+	}
+	if !sess.job.Input.IgnoreRobots {
+		if allowed, err := e.robots.Allowed(ctx, link); err == nil && !allowed {
+			return &redirectRejected{url: link, reason: model.SkipReasonRobots}
+		}
+	}
+	return nil
+}
+
+// mediaType returns the lowercased media type of a Content-Type header, without
+// parameters. A header that does not parse yields its trimmed raw value.
+func mediaType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mt
+}
+
+// contentHash returns the hex SHA-256 of body.
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// fetchFailed decides what happens to a task whose fetch failed: transient failures
+// go back to the frontier for a later attempt, until the job's MaxAttempts is used up.
+func (e *Engine) fetchFailed(ctx context.Context, sess *crawlSession, task *model.URLTask, err error) model.FrontierState {
+	if ctx.Err() != nil {
+		return model.FrontierQueued
+	}
+	var rejected *redirectRejected
+	if errors.As(err, &rejected) {
+		fmt.Println("[crawl] Skipping", task.URL+":", err)
+		e.recordSkip(ctx, sess.job.ID, rejected.url, rejected.reason)
+		return model.FrontierDone
+	}
+	var fe *fetchError
+	if !errors.As(err, &fe) || !fe.retryable {
+		fmt.Println("[crawl] Skipping", task.URL+":", err)
+		if fe != nil && fe.skip != "" {
+			e.recordSkip(ctx, sess.job.ID, task.URL, fe.skip)
 		}
+		return model.FrontierDone
+	}
+	if retry, ok := sess.retry(ctx, task, fe.retryAfter); ok {
+		fmt.Println("[crawl] Retrying", task.URL, "after", err, "- attempt", retry.Attempt+1, "at", retry.NotBefore.Format(time.RFC3339))
+		return model.FrontierQueued
 	}
+	fmt.Println("[crawl] Giving up on", task.URL, "after", task.Attempt+1, "attempts:", err)
+	e.recordSkip(ctx, sess.job.ID, task.URL, model.SkipReasonFetchFailed)
+	return model.FrontierFailed
+}
 
+// recordFetch logs a request. It does not use ctx's cancellation so requests cut
+// short by cancelling the job are logged too. Failures are logged and otherwise ignored.
+func (e *Engine) recordFetch(ctx context.Context, rec *model.Fetch) {
+	if err := e.fetchLog.RecordFetch(context.WithoutCancel(ctx), rec); err != nil {
+		fmt.Println("[crawl] Error recording fetch:", err)
+	}
+}
+
+// recordRobotsFetch logs a robots.txt request for the job found in ctx. A 4xx is how
+// a site says it has no robots.txt, so only transport errors and 5xx are failures.
+func (e *Engine) recordRobotsFetch(ctx context.Context, f robots.FetchInfo) {
+	jobID, ok := ctx.Value(jobIDKey{}).(string)
+	if !ok {
+		return
+	}
+	rec := &model.Fetch{
+		JobID:       jobID,
+		URL:         f.URL,
+		Method:      http.MethodGet,
+		Attempt:     1,
+		StatusCode:  f.StatusCode,
+		LatencyMs:   int(f.Latency.Milliseconds()),
+		Bytes:       f.Bytes,
+		ContentType: f.ContentType,
+	}
+	switch {
+	case f.Err != nil:
+		rec.ErrorClass, rec.Error = classifyTransport(f.Err), f.Err.Error()
+	case f.StatusCode >= 500:
+		rec.ErrorClass, rec.Error = model.FetchErrorHTTPStatus, fmt.Sprintf("HTTP %d", f.StatusCode)
+	}
+	e.recordFetch(ctx, rec)
+}
+
+// recordSkip stores why a URL was not fetched. Failures are logged and otherwise ignored.
+func (e *Engine) recordSkip(ctx context.Context, jobID string, url string, reason model.SkipReason) {
+	if err := e.skipRecorder.RecordSkip(ctx, jobID, url, reason); err != nil {
+		fmt.Println("[crawl] Error recording skipped URL:", err)
+	}
 }
@@ -0,0 +1,615 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-crawler/internal/model"
+)
+
+// memStore is an in-memory implementation of every store the engine writes to.
+type memStore struct {
+	mu       sync.Mutex
+	counts   map[string]int
+	pages    map[string][]*model.Page
+	skips    map[string][]model.SkippedURL
+	frontier map[string][]*model.FrontierEntry
+	fetches  map[string][]model.Fetch
+	links    map[string][]*model.LinkEdge
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		counts:   make(map[string]int),
+		pages:    make(map[string][]*model.Page),
+		skips:    make(map[string][]model.SkippedURL),
+		frontier: make(map[string][]*model.FrontierEntry),
+		fetches:  make(map[string][]model.Fetch),
+		links:    make(map[string][]*model.LinkEdge),
+	}
+}
+
+func newTestEngine(workers int, store *memStore) *Engine {
+	return NewEngine(workers, store, store, store, store, store, store)
+}
+
+func (m *memStore) TryIncrementPagesCrawled(ctx context.Context, jobID string, maxPages int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.counts[jobID] >= maxPages {
+		return false, nil
+	}
+	m.counts[jobID]++
+	return true, nil
+}
+
+func (m *memStore) CreatePage(ctx context.Context, page *model.Page) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pages[page.JobID] = append(m.pages[page.JobID], page)
+	return nil
+}
+
+func (m *memStore) RecordSkip(ctx context.Context, jobID string, url string, reason model.SkipReason) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skips[jobID] = append(m.skips[jobID], model.SkippedURL{JobID: jobID, URL: url, Reason: reason})
+	return nil
+}
+
+func (m *memStore) RecordFetch(ctx context.Context, fetch *model.Fetch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fetches[fetch.JobID] = append(m.fetches[fetch.JobID], *fetch)
+	return nil
+}
+
+func (m *memStore) RecordLinks(ctx context.Context, jobID string, links []*model.LinkEdge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.links[jobID] = append(m.links[jobID], links...)
+	return nil
+}
+
+func (m *memStore) AddFrontierTask(ctx context.Context, jobID string, task *model.URLTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entry(jobID, task.URL) == nil {
+		m.frontier[jobID] = append(m.frontier[jobID], &model.FrontierEntry{
+			JobID:          jobID,
+			URL:            task.URL,
+			Depth:          task.Depth,
+			State:          model.FrontierQueued,
+			DiscoveredFrom: task.DiscoveredFrom,
+		})
+	}
+	return nil
+}
+
+func (m *memStore) SetFrontierState(ctx context.Context, jobID string, url string, state model.FrontierState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entry(jobID, url); e != nil {
+		e.State = state
+	}
+	return nil
+}
+
+func (m *memStore) RetryFrontierTask(ctx context.Context, jobID string, task *model.URLTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e := m.entry(jobID, task.URL); e != nil {
+		e.State = model.FrontierQueued
+		e.Attempts = task.Attempt
+		e.NextAttemptAt = task.NotBefore
+	}
+	return nil
+}
+
+func (m *memStore) LoadFrontier(ctx context.Context, jobID string) ([]*model.FrontierEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]*model.FrontierEntry, len(m.frontier[jobID]))
+	for i, e := range m.frontier[jobID] {
+		c := *e
+		out[i] = &c
+	}
+	return out, nil
+}
+
+// entry returns jobID's frontier entry for url. Must be called with m.mu held.
+func (m *memStore) entry(jobID, url string) *model.FrontierEntry {
+	for _, e := range m.frontier[jobID] {
+		if e.URL == url {
+			return e
+		}
+	}
+	return nil
+}
+
+// pageURLs returns the sorted URLs of the pages saved for jobID.
+func (m *memStore) pageURLs(jobID string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var urls []string
+	for _, p := range m.pages[jobID] {
+		urls = append(urls, p.URL)
+	}
+	slices.Sort(urls)
+	return urls
+}
+
+// frontierStates returns the state of each URL in jobID's frontier.
+func (m *memStore) frontierStates(jobID string) map[string]model.FrontierState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	states := make(map[string]model.FrontierState)
+	for _, e := range m.frontier[jobID] {
+		states[e.URL] = e.State
+	}
+	return states
+}
+
+// linkSite serves a small site: every page links to /p1, /p2, /p3 and /secret, which
+// robots.txt disallows.
+func linkSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /secret\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, `<html><head><title>%s</title></head><body>
+<a href="/p1">one</a> <a href="/p2">two</a> <a href="/p3">three</a> <a href="/secret">secret</a>
+</body></html>`, r.URL.Path)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestConcurrentJobs runs many jobs at once on one Engine; run it with -race.
+func TestConcurrentJobs(t *testing.T) {
+	const jobs = 8
+	sites := []*httptest.Server{linkSite(t), linkSite(t)}
+	store := newMemStore()
+	e := newTestEngine(4, store)
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			job := &model.CrawlJob{
+				ID: fmt.Sprint("job-", i),
+				Input: model.CrawlInput{
+					StartURL:             sites[i%len(sites)].URL + "/",
+					MaxDepth:             2,
+					MaxPages:             10,
+					MaxConcurrentPerHost: 2,
+				},
+			}
+			if err := e.Start(context.Background(), job); err != nil {
+				t.Errorf("%s: %v", job.ID, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < jobs; i++ {
+		id := fmt.Sprint("job-", i)
+		base := sites[i%len(sites)].URL
+		want := []string{base + "/", base + "/p1", base + "/p2", base + "/p3"}
+		if got := store.pageURLs(id); !slices.Equal(got, want) {
+			t.Errorf("%s: pages = %v, want %v", id, got, want)
+		}
+		for url, state := range store.frontierStates(id) {
+			if state != model.FrontierDone {
+				t.Errorf("%s: %s left %s", id, url, state)
+			}
+		}
+		store.mu.Lock()
+		skips := store.skips[id]
+		store.mu.Unlock()
+		if len(skips) != 1 || skips[0].URL != base+"/secret" || skips[0].Reason != model.SkipReasonRobots {
+			t.Errorf("%s: skips = %+v, want only /secret disallowed by robots.txt", id, skips)
+		}
+	}
+}
+
+// TestRedirectTargetFinished checks that a redirect target claimed by a task that
+// then stops short of saving a page does not stay QUEUED in the frontier.
+func TestRedirectTargetFinished(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/old">old</a></body></html>`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "redirect", Input: model.CrawlInput{
+		StartURL:     srv.URL + "/",
+		MaxDepth:     1,
+		MaxPages:     1, // the redirected page is fetched but not saved
+		IgnoreRobots: true,
+	}}
+	if err := newTestEngine(1, store).Start(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	states := store.frontierStates(job.ID)
+	if got := states[srv.URL+"/new"]; got != model.FrontierDone {
+		t.Errorf("redirect target state = %q, want DONE (frontier %v)", got, states)
+	}
+	for url, state := range states {
+		if state == model.FrontierQueued {
+			t.Errorf("%s left QUEUED", url)
+		}
+	}
+}
+
+// TestFetchLog checks that robots.txt requests and HEAD probes are logged next to
+// the page GETs, and that a URL rejected by its HEAD is not fetched again with GET.
+func TestFetchLog(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nAllow: /\n")
+	})
+	mux.HandleFunc("/doc.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body><a href="/p1">one</a> <a href="/doc.pdf">pdf</a></body></html>`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "fetches", Input: model.CrawlInput{
+		StartURL:    srv.URL + "/",
+		MaxDepth:    1,
+		MaxPages:    10,
+		HeadRequest: true,
+	}}
+	if err := newTestEngine(1, store).Start(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]model.Fetch)
+	store.mu.Lock()
+	for _, f := range store.fetches[job.ID] {
+		key := f.Method + " " + strings.TrimPrefix(f.URL, srv.URL)
+		if _, dup := got[key]; dup {
+			t.Errorf("%s logged twice", key)
+		}
+		got[key] = f
+	}
+	store.mu.Unlock()
+
+	want := map[string]model.FetchErrorClass{
+		"GET /robots.txt": "",
+		"HEAD /":          "",
+		"GET /":           "",
+		"HEAD /p1":        "",
+		"GET /p1":         "",
+		"HEAD /doc.pdf":   model.FetchErrorContentType,
+	}
+	for key, class := range want {
+		f, ok := got[key]
+		if !ok {
+			t.Errorf("%s not logged", key)
+			continue
+		}
+		if f.StatusCode != http.StatusOK || f.ErrorClass != class || f.Attempt != 1 {
+			t.Errorf("%s logged as %+v, want status 200, class %q, attempt 1", key, f, class)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("logged %d requests, want %d: %v", len(got), len(want), got)
+	}
+}
+
+// TestRobotsFetchFailureLogged checks that an unreachable robots.txt is logged as a
+// failed request.
+func TestRobotsFetchFailureLogged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "robots-down", Input: model.CrawlInput{StartURL: srv.URL + "/", MaxPages: 1}}
+	if err := newTestEngine(1, store).Start(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	fetches := store.fetches[job.ID]
+	if len(fetches) != 1 {
+		t.Fatalf("fetches = %+v, want only the robots.txt request", fetches)
+	}
+	f := fetches[0]
+	if f.URL != srv.URL+"/robots.txt" || f.StatusCode != http.StatusServiceUnavailable ||
+		f.ErrorClass != model.FetchErrorHTTPStatus || !f.ErrorClass.Failed() {
+		t.Errorf("robots.txt fetch logged as %+v, want a failed HTTP_STATUS 503", f)
+	}
+}
+
+// redirectingRobotsSites returns a start server that redirects every page to the same
+// path on the other server, whose robots.txt is itself a redirect to /static/robots.txt.
+func redirectingRobotsSites(t *testing.T) (start, target *httptest.Server) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/static/robots.txt", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/static/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<html><body>docs</body></html>`)
+	})
+	target = httptest.NewServer(mux)
+	t.Cleanup(target.Close)
+	start = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, target.URL+r.URL.Path, http.StatusFound)
+	}))
+	t.Cleanup(start.Close)
+	return start, target
+}
+
+// startWithin runs job and fails the test if it does not finish in time.
+func startWithin(t *testing.T, e *Engine, job *model.CrawlJob, d time.Duration) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- e.Start(context.Background(), job) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(d):
+		t.Fatal("crawl did not finish")
+	}
+}
+
+// TestRedirectToRedirectingRobots follows a page redirect to an origin whose
+// robots.txt redirects. The robots.txt request must not be vetted or traced as a
+// page redirect: it must not deadlock, fall foul of URL rules, or show up in the
+// page's RedirectChain.
+func TestRedirectToRedirectingRobots(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []model.URLRule
+	}{
+		{"no rules", nil},
+		{"include docs", []model.URLRule{{Action: model.URLRuleInclude, Pattern: "/docs/**"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, target := redirectingRobotsSites(t)
+			store := newMemStore()
+			job := &model.CrawlJob{ID: "robots-redirect", Input: model.CrawlInput{
+				StartURL: start.URL + "/docs/a",
+				Scope:    model.ScopeUnrestricted,
+				URLRules: tt.rules,
+				MaxPages: 10,
+			}}
+			startWithin(t, newTestEngine(1, store), job, 5*time.Second)
+
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			pages := store.pages[job.ID]
+			if len(pages) != 1 || pages[0].URL != target.URL+"/docs/a" {
+				t.Fatalf("pages = %v, want only %s/docs/a (skips %+v)", pages, target.URL, store.skips[job.ID])
+			}
+			want := []model.RedirectHop{{From: start.URL + "/docs/a", To: target.URL + "/docs/a", StatusCode: http.StatusFound}}
+			if got := pages[0].RedirectChain; !slices.Equal(got, want) {
+				t.Errorf("RedirectChain = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestLinkGraphFollowedOnly checks that only links the crawl followed are recorded:
+// pages at MaxDepth add no edges.
+func TestLinkGraphFollowedOnly(t *testing.T) {
+	srv := linkSite(t)
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "links", Input: model.CrawlInput{
+		StartURL: srv.URL + "/",
+		MaxDepth: 1,
+		MaxPages: 10,
+	}}
+	if err := newTestEngine(2, store).Start(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	var got []string
+	for _, l := range store.links[job.ID] {
+		got = append(got, fmt.Sprintf("%s -> %s @%d",
+			strings.TrimPrefix(l.SourceURL, srv.URL), strings.TrimPrefix(l.TargetURL, srv.URL), l.Depth))
+		if e := store.entry(job.ID, l.TargetURL); e == nil {
+			t.Errorf("edge to %s, which is not in the frontier", l.TargetURL)
+		}
+	}
+	slices.Sort(got)
+	want := []string{"/ -> /p1 @1", "/ -> /p2 @1", "/ -> /p3 @1", "/ -> /secret @1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("edges = %v, want %v", got, want)
+	}
+}
+
+// TestNoIndexPagesNotCounted checks that noindex pages do not use up MaxPages and that
+// a nofollow page's canonical link is not followed.
+func TestNoIndexPagesNotCounted(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `<html><head><meta name="robots" content="noindex"></head><body>
+<a href="/a">a</a> <a href="/b">b</a> <a href="/nofollow">nofollow</a></body></html>`)
+		case "/nofollow":
+			fmt.Fprint(w, `<html><head><meta name="robots" content="nofollow">
+<link rel="canonical" href="/canonical"></head><body><a href="/c">c</a></body></html>`)
+		default:
+			fmt.Fprint(w, `<html><body>page</body></html>`)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "noindex", Input: model.CrawlInput{
+		StartURL:        srv.URL + "/",
+		MaxDepth:        2,
+		MaxPages:        3,
+		FollowCanonical: true,
+		IgnoreRobots:    true,
+	}}
+	if err := newTestEngine(1, store).Start(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{srv.URL + "/a", srv.URL + "/b", srv.URL + "/nofollow"}
+	if got := store.pageURLs(job.ID); !slices.Equal(got, want) {
+		t.Errorf("pages = %v, want %v", got, want)
+	}
+	if _, ok := store.frontierStates(job.ID)[srv.URL+"/canonical"]; ok {
+		t.Error("canonical of a nofollow page was enqueued")
+	}
+}
+
+// contentSite serves pages that exercise the job's content policy. Handlers that set
+// Content-Type to nil keep net/http from sniffing one, so the response has none.
+func contentSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	const page = `<html><head><title>untyped</title></head><body>` +
+		`<a href="/untyped">1</a> <a href="/binary">2</a> <a href="/declared-big">3</a> <a href="/chunked-big">4</a>` +
+		`</body></html>`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", http.NotFound)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			http.Error(w, "no HEAD here", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, page)
+	})
+	mux.HandleFunc("/untyped", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		fmt.Fprint(w, page)
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		fmt.Fprint(w, "\x89PNG\r\n\x1a\n"+strings.Repeat("\x00", 100))
+	})
+	mux.HandleFunc("/declared-big", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Content-Length", "2000")
+		fmt.Fprint(w, strings.Repeat(" ", 2000))
+	})
+	mux.HandleFunc("/chunked-big", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		for i := 0; i < 4; i++ {
+			fmt.Fprint(w, strings.Repeat(" ", 500))
+			w.(http.Flusher).Flush() // no Content-Length: the size is only known by reading
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// TestContentPolicy checks HEAD probes refused with 405, sniffing of responses without
+// a Content-Type, and the body size cap, declared or not.
+func TestContentPolicy(t *testing.T) {
+	for _, head := range []bool{false, true} {
+		t.Run(fmt.Sprint("HeadRequest=", head), func(t *testing.T) {
+			srv := contentSite(t)
+			store := newMemStore()
+			job := &model.CrawlJob{ID: "content", Input: model.CrawlInput{
+				StartURL:     srv.URL + "/",
+				MaxDepth:     1,
+				MaxPages:     10,
+				MaxBodyBytes: 1000,
+				HeadRequest:  head,
+			}}
+			if err := newTestEngine(1, store).Start(context.Background(), job); err != nil {
+				t.Fatal(err)
+			}
+
+			want := []string{srv.URL + "/", srv.URL + "/untyped"}
+			if got := store.pageURLs(job.ID); !slices.Equal(got, want) {
+				t.Errorf("pages = %v, want %v", got, want)
+			}
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			for _, p := range store.pages[job.ID] {
+				if strings.HasSuffix(p.URL, "/untyped") && p.ContentType != "text/html" {
+					t.Errorf("untyped page stored as %q, want sniffed text/html", p.ContentType)
+				}
+			}
+
+			got := make(map[string]model.Fetch)
+			for _, f := range store.fetches[job.ID] {
+				got[f.Method+" "+strings.TrimPrefix(f.URL, srv.URL)] = f
+			}
+			type outcome struct {
+				status int
+				class  model.FetchErrorClass
+			}
+			wantFetches := map[string]outcome{
+				"GET /":             {http.StatusOK, ""},
+				"GET /untyped":      {http.StatusOK, ""},
+				"GET /binary":       {http.StatusOK, model.FetchErrorContentType},
+				"GET /chunked-big":  {http.StatusOK, model.FetchErrorTooLarge},
+				"GET /declared-big": {http.StatusOK, model.FetchErrorTooLarge},
+			}
+			if head {
+				// A refused HEAD is logged but the GET still decides; a HEAD that
+				// declares an oversized body stops the URL before any GET.
+				wantFetches["HEAD /"] = outcome{http.StatusMethodNotAllowed, model.FetchErrorHTTPStatus}
+				wantFetches["HEAD /declared-big"] = outcome{http.StatusOK, model.FetchErrorTooLarge}
+				delete(wantFetches, "GET /declared-big")
+			}
+			for key, w := range wantFetches {
+				f, ok := got[key]
+				if !ok {
+					t.Errorf("%s not logged", key)
+					continue
+				}
+				if f.StatusCode != w.status || f.ErrorClass != w.class {
+					t.Errorf("%s logged with status %d, class %q; want %d, %q", key, f.StatusCode, f.ErrorClass, w.status, w.class)
+				}
+				if f.ErrorClass == model.FetchErrorTooLarge && f.Bytes != 0 {
+					t.Errorf("%s kept %d bytes of an oversized body", key, f.Bytes)
+				}
+			}
+			if _, ok := got["GET /declared-big"]; head && ok {
+				t.Error("GET /declared-big sent after its HEAD declared an oversized body")
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package crawl
+
+import (
+	"fmt"
+	"go-crawler/internal/model"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// extractor applies a job's extraction rules to parsed pages.
+type extractor struct {
+	rules []extractionRule
+}
+
+type extractionRule struct {
+	model.ExtractionRule
+	sel selector
+}
+
+// newExtractor compiles rules. It returns nil if there are none.
+func newExtractor(rules []model.ExtractionRule) (*extractor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	x := &extractor{}
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("extraction rule with selector %q has no name", r.Selector)
+		}
+		if seen[r.Name] {
+			return nil, fmt.Errorf("duplicate extraction rule %q", r.Name)
+		}
+		seen[r.Name] = true
+		switch r.Mode {
+		case "":
+			r.Mode = model.ExtractText
+		case model.ExtractText:
+		case model.ExtractAttribute:
+			if r.Attribute == "" {
+				return nil, fmt.Errorf("extraction rule %q: mode %s requires an attribute", r.Name, r.Mode)
+			}
+			r.Attribute = strings.ToLower(r.Attribute)
+		default:
+			return nil, fmt.Errorf("extraction rule %q: unknown mode %q", r.Name, r.Mode)
+		}
+		sel, err := compileSelector(r.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("extraction rule %q: %w", r.Name, err)
+		}
+		x.rules = append(x.rules, extractionRule{ExtractionRule: r, sel: sel})
+	}
+	return x, nil
+}
+
+// extract evaluates every rule on doc. Each rule has a key in the result, so all pages
+// of a job have the same fields. A nil extractor extracts nothing.
+func (x *extractor) extract(doc *html.Node) map[string]any {
+	if x == nil {
+		return nil
+	}
+	out := make(map[string]any, len(x.rules))
+	for _, r := range x.rules {
+		values := []string{}
+		for _, n := range r.sel.selectAll(doc) {
+			if r.Mode == model.ExtractAttribute {
+				if v, ok := attr(n, r.Attribute); ok {
+					values = append(values, strings.TrimSpace(v))
+				}
+			} else {
+				values = append(values, innerText(n))
+			}
+			if !r.Multiple && len(values) > 0 {
+				break
+			}
+		}
+		switch {
+		case r.Multiple:
+			out[r.Name] = values
+		case len(values) > 0:
+			out[r.Name] = values[0]
+		default:
+			out[r.Name] = nil
+		}
+	}
+	return out
+}
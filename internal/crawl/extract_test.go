@@ -0,0 +1,84 @@
+package crawl
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func TestNewExtractorErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []model.ExtractionRule
+	}{
+		{"no name", []model.ExtractionRule{{Selector: "h1"}}},
+		{"duplicate name", []model.ExtractionRule{{Name: "a", Selector: "h1"}, {Name: "a", Selector: "h2"}}},
+		{"attribute mode without attribute", []model.ExtractionRule{{Name: "a", Selector: "a", Mode: model.ExtractAttribute}}},
+		{"unknown mode", []model.ExtractionRule{{Name: "a", Selector: "a", Mode: "HTML"}}},
+		{"bad selector", []model.ExtractionRule{{Name: "a", Selector: "a["}}},
+	}
+	for _, tt := range tests {
+		if _, err := newExtractor(tt.rules); err == nil {
+			t.Errorf("%s: newExtractor succeeded, want an error", tt.name)
+		}
+	}
+	if x, err := newExtractor(nil); x != nil || err != nil {
+		t.Errorf("newExtractor(nil) = %v, %v; want nil, nil", x, err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	x, err := newExtractor([]model.ExtractionRule{
+		{Name: "title", Selector: "h1"},
+		{Name: "price", Selector: ".price", Mode: model.ExtractText},
+		{Name: "tags", Selector: "ul.tags li", Multiple: true},
+		{Name: "links", Selector: "a", Mode: model.ExtractAttribute, Attribute: "HREF", Multiple: true},
+		{Name: "image", Selector: "img", Mode: model.ExtractAttribute, Attribute: "src"},
+		{Name: "missing", Selector: "#nope"},
+		{Name: "none", Selector: "#nope", Multiple: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := parseHTML(t, `<html><body>
+<h1>  Blue <b>Widget</b> </h1><h1>Second</h1>
+<p class="price">€ 12.50</p>
+<ul class="tags"><li>tools</li><li> home </li></ul>
+<a href=" /a ">a</a><a name="anchor">no href</a><a href="/b">b</a>
+<img alt="no src"><img src="/w.png">
+</body></html>`)
+
+	got := x.extract(doc)
+	want := map[string]any{
+		"title":   "Blue Widget",
+		"price":   "€ 12.50",
+		"tags":    []string{"tools", "home"},
+		"links":   []string{"/a", "/b"},
+		"image":   "/w.png",
+		"missing": nil,
+		"none":    []string{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extract = %#v\nwant %#v", got, want)
+	}
+
+	// The download is one JSON object per page: rules without a match are null, and
+	// Multiple rules are always lists.
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(&model.ExtractedRecord{PageID: 7, URL: "http://example.com/w", Data: got}); err != nil {
+		t.Fatal(err)
+	}
+	const line = `{"PageID":7,"URL":"http://example.com/w","Data":{"image":"/w.png","links":["/a","/b"],"missing":null,"none":[],"price":"€ 12.50","tags":["tools","home"],"title":"Blue Widget"}}`
+	if s := strings.TrimSuffix(buf.String(), "\n"); s != line {
+		t.Errorf("JSON line = %s\nwant %s", s, line)
+	}
+
+	var nilExtractor *extractor
+	if got := nilExtractor.extract(doc); got != nil {
+		t.Errorf("nil extractor extracted %v", got)
+	}
+}
@@ -0,0 +1,250 @@
+package crawl
+
+import (
+	"fmt"
+	"go-crawler/internal/model"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// followedLinkRels are the <link rel> values that point at other pages worth crawling
+// (translations, feeds, pagination) rather than at stylesheets, icons or preloads.
+var followedLinkRels = map[string]bool{"alternate": true, "next": true, "prev": true, "previous": true}
+
+// resourceLinkRels mark a <link> as a page resource even next to a followed rel, as in
+// rel="alternate stylesheet" or rel="alternate icon"; such links are never followed.
+var resourceLinkRels = map[string]bool{
+	"stylesheet": true, "icon": true, "apple-touch-icon": true, "apple-touch-icon-precomposed": true, "mask-icon": true,
+}
+
+// defaultLinkSources are followed when a job does not choose its own.
+var defaultLinkSources = []model.LinkSource{model.LinkSourceAnchor}
+
+// newLinkSources returns the set of link sources a job follows.
+func newLinkSources(input model.CrawlInput) (map[model.LinkSource]bool, error) {
+	sources := input.LinkSources
+	if len(sources) == 0 {
+		sources = defaultLinkSources
+	}
+	set := make(map[model.LinkSource]bool, len(sources))
+	for _, src := range sources {
+		switch src {
+		case model.LinkSourceAnchor, model.LinkSourceArea, model.LinkSourceLink, model.LinkSourceIframe,
+			model.LinkSourceFrame, model.LinkSourceForm, model.LinkSourceSrcset:
+			set[src] = true
+		default:
+			return nil, fmt.Errorf("unknown link source %q", src)
+		}
+	}
+	return set, nil
+}
+
+// extractLinks returns every http(s) link in doc from all link sources, in document
+// order, resolved against base (see documentBase).
+func extractLinks(doc *html.Node, base *url.URL) []model.Link {
+	var links []model.Link
+	add := func(raw string, source model.LinkSource, rel []string, text string) {
+		if abs, ok := resolveLink(base, raw); ok {
+			links = append(links, model.Link{URL: abs, Source: source, Rel: rel, Text: text})
+		}
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+			switch n.Data {
+			case "a":
+				if href, ok := attr(n, "href"); ok {
+					add(href, model.LinkSourceAnchor, rel, anchorText(n))
+				}
+			case "area":
+				if href, ok := attr(n, "href"); ok {
+					add(href, model.LinkSourceArea, rel, attrValue(n, "alt"))
+				}
+			case "link":
+				if href, ok := attr(n, "href"); ok && hasAny(rel, followedLinkRels) && !hasAny(rel, resourceLinkRels) {
+					add(href, model.LinkSourceLink, rel, attrValue(n, "title"))
+				}
+			case "iframe", "frame":
+				source := model.LinkSourceIframe
+				if n.Data == "frame" {
+					source = model.LinkSourceFrame
+				}
+				if src, ok := attr(n, "src"); ok {
+					add(src, source, nil, attrValue(n, "title"))
+				}
+			case "form":
+				method := strings.ToLower(strings.TrimSpace(attrValue(n, "method")))
+				if action, ok := attr(n, "action"); ok && (method == "" || method == "get") {
+					add(action, model.LinkSourceForm, rel, "")
+				}
+			case "img", "source":
+				for _, candidate := range parseSrcset(attrValue(n, "srcset")) {
+					add(candidate, model.LinkSourceSrcset, nil, attrValue(n, "alt"))
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return links
+}
+
+// extractCanonical returns the first <link rel="canonical"> target in doc, resolved
+// against base, or "" if there is none.
+func extractCanonical(doc *html.Node, base *url.URL) string {
+	var canonical string
+	var find func(*html.Node) bool
+	find = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.Data == "link" {
+			rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+			if href, ok := attr(n, "href"); ok && slices.Contains(rel, "canonical") {
+				if abs, ok := resolveLink(base, href); ok {
+					canonical = abs
+					return true
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if find(c) {
+				return true
+			}
+		}
+		return false
+	}
+	find(doc)
+	return canonical
+}
+
+// resolveLink resolves an attribute value against base and reports whether it is an
+// http(s) URL.
+func resolveLink(base *url.URL, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	abs := base.ResolveReference(ref)
+	if abs.Scheme != "http" && abs.Scheme != "https" {
+		return "", false
+	}
+	return abs.String(), true
+}
+
+// documentBase returns the URL relative links resolve against: the first <base href>
+// in the document, itself resolved against pageURL, or pageURL when there is none.
+func documentBase(doc *html.Node, pageURL *url.URL) *url.URL {
+	var base *url.URL
+	var find func(*html.Node) bool
+	find = func(n *html.Node) bool {
+		if n.Type == html.ElementNode && n.Data == "base" {
+			if href, ok := attr(n, "href"); ok {
+				if ref, err := url.Parse(strings.TrimSpace(href)); err == nil {
+					base = pageURL.ResolveReference(ref)
+				}
+				return true
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if find(c) {
+				return true
+			}
+		}
+		return false
+	}
+	find(doc)
+	if base == nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return pageURL
+	}
+	return base
+}
+
+// anchorText is the visible text of an <a>, falling back to its aria-label or title,
+// then to the alt text of an image inside it.
+func anchorText(n *html.Node) string {
+	if text := innerText(n); text != "" {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	for _, key := range []string{"aria-label", "title"} {
+		if v := strings.TrimSpace(attrValue(n, key)); v != "" {
+			return v
+		}
+	}
+	var alt string
+	var find func(*html.Node)
+	find = func(n *html.Node) {
+		for c := n.FirstChild; c != nil && alt == ""; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "img" {
+				alt = strings.TrimSpace(attrValue(c, "alt"))
+			}
+			find(c)
+		}
+	}
+	find(n)
+	return alt
+}
+
+// parseSrcset returns the URLs of a srcset attribute's image candidates. Each
+// candidate is a URL optionally followed by a width or density descriptor, and
+// candidates are separated by commas; a URL may itself contain commas.
+func parseSrcset(srcset string) []string {
+	var urls []string
+	s := srcset
+	for {
+		s = strings.TrimLeft(s, " \t\n\r\f,")
+		if s == "" {
+			return urls
+		}
+		end := strings.IndexAny(s, " \t\n\r\f")
+		if end < 0 {
+			end = len(s)
+		}
+		candidate := s[:end]
+		s = s[end:]
+		if trimmed := strings.TrimRight(candidate, ","); trimmed != candidate {
+			// A URL ending in commas has no descriptor; the commas separate candidates.
+			candidate = trimmed
+		} else if i := strings.IndexByte(s, ','); i >= 0 {
+			s = s[i+1:] // skip the descriptor
+		} else {
+			s = ""
+		}
+		if candidate != "" {
+			urls = append(urls, candidate)
+		}
+	}
+}
+
+// attr returns the value of n's attribute key and whether it is present.
+func attr(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of n's attribute key, or "" if it is absent.
+func attrValue(n *html.Node, key string) string {
+	v, _ := attr(n, key)
+	return v
+}
+
+func hasAny(values []string, set map[string]bool) bool {
+	for _, v := range values {
+		if set[v] {
+			return true
+		}
+	}
+	return false
+}
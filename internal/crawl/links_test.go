@@ -0,0 +1,83 @@
+package crawl
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		srcset string
+		want   []string
+	}{
+		{"", nil},
+		{"a.png", []string{"a.png"}},
+		{"a.png 1x, b.png 2x", []string{"a.png", "b.png"}},
+		{"small.jpg 480w,large.jpg 1080w", []string{"small.jpg", "large.jpg"}},
+		{"a.png,b.png", []string{"a.png,b.png"}}, // a comma inside a URL is part of it
+		{"  a.png  1x ,\n b.png 2x  ", []string{"a.png", "b.png"}},
+		{"/img?size=1,2 1x, /img?size=3,4 2x", []string{"/img?size=1,2", "/img?size=3,4"}},
+		{"a.png 1x,,, b.png", []string{"a.png", "b.png"}},
+	}
+	for _, tt := range tests {
+		if got := parseSrcset(tt.srcset); !slices.Equal(got, tt.want) {
+			t.Errorf("parseSrcset(%q) = %q, want %q", tt.srcset, got, tt.want)
+		}
+	}
+}
+
+func TestDocumentBase(t *testing.T) {
+	page, _ := url.Parse("https://example.com/dir/page.html")
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"none", ``, "https://example.com/dir/page.html"},
+		{"absolute", `<base href="https://cdn.example.com/root/">`, "https://cdn.example.com/root/"},
+		{"relative", `<base href="../other/">`, "https://example.com/other/"},
+		{"root relative", `<base href="/root/">`, "https://example.com/root/"},
+		{"first wins", `<base href="/one/"><base href="/two/">`, "https://example.com/one/"},
+		{"target only", `<base target="_blank"><base href="/root/">`, "https://example.com/root/"},
+		{"invalid scheme", `<base href="javascript:void(0)">`, "https://example.com/dir/page.html"},
+		{"other scheme", `<base href="ftp://example.com/">`, "https://example.com/dir/page.html"},
+		{"unparsable", `<base href="http://[::1">`, "https://example.com/dir/page.html"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseHTML(t, "<html><head>"+tt.head+"</head><body></body></html>")
+			if got := documentBase(doc, page).String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractLinkElements(t *testing.T) {
+	doc := parseHTML(t, `<html><head>
+<link rel="alternate" hreflang="de" href="/de/">
+<link rel="next" href="/page/2">
+<link rel="Prev" href="/page/0">
+<link rel="stylesheet" href="/main.css">
+<link rel="alternate stylesheet" href="/contrast.css">
+<link rel="icon" href="/favicon.ico">
+<link rel="alternate icon" href="/favicon.svg">
+<link rel="shortcut icon" href="/favicon2.ico">
+<link rel="preload" href="/font.woff2">
+<link rel="canonical" href="/canonical">
+</head><body></body></html>`)
+	base, _ := url.Parse("https://example.com/")
+	var got []string
+	for _, l := range extractLinks(doc, base) {
+		if l.Source == model.LinkSourceLink {
+			got = append(got, l.URL)
+		}
+	}
+	want := []string{"https://example.com/de/", "https://example.com/page/2", "https://example.com/page/0"}
+	if !slices.Equal(got, want) {
+		t.Errorf("followed <link>s = %q, want %q", got, want)
+	}
+}
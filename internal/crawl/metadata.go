@@ -0,0 +1,176 @@
+package crawl
+
+import (
+	"encoding/json"
+	"go-crawler/internal/model"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// openGraphPrefixes are the property namespaces of the Open Graph protocol.
+var openGraphPrefixes = []string{"og:", "article:", "book:", "profile:", "music:", "video:"}
+
+// Caps on what one page can add to its metadata.
+const (
+	maxJSONLDBytes    = 256 << 10 // larger JSON-LD blocks are skipped
+	maxJSONLDItems    = 50        // JSON-LD nodes kept per page
+	maxPropertyValues = 20        // values kept per Open Graph or Twitter property
+)
+
+// extractMetadata reads doc's structured metadata. hreflang URLs are resolved against
+// base. Without a meta description, og:description and then twitter:description are
+// used. Each JSON-LD node is kept as its own item, up to maxJSONLDItems; invalid or
+// oversized blocks are skipped.
+func extractMetadata(doc *html.Node, base *url.URL) model.PageMetadata {
+	var md model.PageMetadata
+	var contentLanguage string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.Data {
+			case "html":
+				if md.Lang == "" {
+					md.Lang = strings.TrimSpace(attrValue(n, "lang"))
+				}
+			case "meta":
+				content := strings.TrimSpace(attrValue(n, "content"))
+				name := strings.ToLower(strings.TrimSpace(attrValue(n, "name")))
+				property := strings.ToLower(strings.TrimSpace(attrValue(n, "property")))
+				if property == "" {
+					property = name // many sites put og: and twitter: keys in name
+				}
+				switch {
+				case name == "description" && md.Description == "":
+					md.Description = content
+				case name == "keywords":
+					for _, kw := range strings.Split(content, ",") {
+						if kw = strings.TrimSpace(kw); kw != "" {
+							md.Keywords = append(md.Keywords, kw)
+						}
+					}
+				case strings.EqualFold(attrValue(n, "http-equiv"), "content-language"):
+					contentLanguage = content
+				case strings.HasPrefix(property, "twitter:"):
+					md.Twitter = appendProperty(md.Twitter, property, content)
+				case slices.ContainsFunc(openGraphPrefixes, func(p string) bool { return strings.HasPrefix(property, p) }):
+					md.OpenGraph = appendProperty(md.OpenGraph, property, content)
+				}
+			case "link":
+				rel := strings.Fields(strings.ToLower(attrValue(n, "rel")))
+				lang := strings.TrimSpace(attrValue(n, "hreflang"))
+				if lang != "" && slices.Contains(rel, "alternate") {
+					if abs, ok := resolveLink(base, attrValue(n, "href")); ok {
+						md.Hreflang = append(md.Hreflang, model.HreflangLink{Lang: lang, URL: abs})
+					}
+				}
+			case "script":
+				if typ, _, _ := strings.Cut(attrValue(n, "type"), ";"); strings.EqualFold(strings.TrimSpace(typ), "application/ld+json") {
+					if v, ok := parseJSONLD(n); ok {
+						for _, item := range jsonLDItems(v) {
+							if len(md.JSONLD) < maxJSONLDItems {
+								md.JSONLD = append(md.JSONLD, item)
+							}
+						}
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if md.Description == "" {
+		md.Description = firstValue(md.OpenGraph, "og:description")
+	}
+	if md.Description == "" {
+		md.Description = firstValue(md.Twitter, "twitter:description")
+	}
+	if md.Lang == "" {
+		// The header form may list several languages; the first is the primary one.
+		first, _, _ := strings.Cut(contentLanguage, ",")
+		md.Lang = strings.TrimSpace(first)
+	}
+	return md
+}
+
+// appendProperty adds a meta property value, keeping repeated properties such as
+// several og:image tags in document order, up to maxPropertyValues.
+func appendProperty(props map[string][]string, key, value string) map[string][]string {
+	if value == "" {
+		return props
+	}
+	if props == nil {
+		props = make(map[string][]string)
+	}
+	if len(props[key]) < maxPropertyValues {
+		props[key] = append(props[key], value)
+	}
+	return props
+}
+
+// firstValue returns the first value of property key, or "" if it has none.
+func firstValue(props map[string][]string, key string) string {
+	if values := props[key]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// parseJSONLD decodes the JSON in a <script type="application/ld+json">. Content
+// wrapped in an HTML comment or CDATA section, as some CMSs emit, is unwrapped.
+func parseJSONLD(script *html.Node) (any, bool) {
+	var raw strings.Builder
+	for c := script.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			raw.WriteString(c.Data)
+		}
+	}
+	if raw.Len() > maxJSONLDBytes {
+		return nil, false
+	}
+	text := strings.TrimSpace(raw.String())
+	for _, wrap := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}, {"//<![CDATA[", "//]]>"}} {
+		if strings.HasPrefix(text, wrap[0]) && strings.HasSuffix(text, wrap[1]) {
+			text = strings.TrimSpace(text[len(wrap[0]) : len(text)-len(wrap[1])])
+		}
+	}
+	text = strings.TrimSuffix(text, ";")
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// jsonLDItems splits a JSON-LD block into its nodes: the elements of a top-level array
+// and of an "@graph" array are items of their own, each keeping the block's @context.
+func jsonLDItems(v any) []any {
+	switch v := v.(type) {
+	case []any:
+		var items []any
+		for _, e := range v {
+			items = append(items, jsonLDItems(e)...)
+		}
+		return items
+	case map[string]any:
+		graph, ok := v["@graph"].([]any)
+		if !ok {
+			return []any{v}
+		}
+		var items []any
+		for _, e := range graph {
+			if node, ok := e.(map[string]any); ok {
+				if _, has := node["@context"]; !has && v["@context"] != nil {
+					node["@context"] = v["@context"]
+				}
+			}
+			items = append(items, jsonLDItems(e)...)
+		}
+		return items
+	}
+	return []any{v}
+}
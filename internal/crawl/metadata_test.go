@@ -0,0 +1,167 @@
+package crawl
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func metadataOf(t *testing.T, head string) model.PageMetadata {
+	t.Helper()
+	base, _ := url.Parse("https://example.com/dir/page")
+	return extractMetadata(parseHTML(t, `<html lang="en"><head>`+head+`</head><body></body></html>`), base)
+}
+
+func TestExtractMetadata(t *testing.T) {
+	md := metadataOf(t, `
+<meta name="description" content=" Page description ">
+<meta name="keywords" content="go, crawler, ,search">
+<meta property="og:title" content="OG title">
+<meta property="og:image" content="/a.png"><meta property="og:image" content="/b.png">
+<meta name="og:description" content="OG description in a name attribute">
+<meta property="article:author" content="Ann">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="">
+<link rel="alternate" hreflang="de" href="/de/page">
+<link rel="alternate" hreflang="fr" href="javascript:void(0)">
+<link rel="alternate" href="/feed.xml">`)
+	want := model.PageMetadata{
+		Description: "Page description",
+		Keywords:    []string{"go", "crawler", "search"},
+		Lang:        "en",
+		OpenGraph: map[string][]string{
+			"og:title":       {"OG title"},
+			"og:image":       {"/a.png", "/b.png"},
+			"og:description": {"OG description in a name attribute"},
+			"article:author": {"Ann"},
+		},
+		Twitter:  map[string][]string{"twitter:card": {"summary"}},
+		Hreflang: []model.HreflangLink{{Lang: "de", URL: "https://example.com/de/page"}},
+	}
+	if !reflect.DeepEqual(md, want) {
+		t.Errorf("got  %+v\nwant %+v", md, want)
+	}
+}
+
+func TestMetadataDescriptionFallback(t *testing.T) {
+	const (
+		meta    = `<meta name="description" content="meta">`
+		og      = `<meta property="og:description" content="og">`
+		twitter = `<meta name="twitter:description" content="twitter">`
+	)
+	tests := []struct {
+		head string
+		want string
+	}{
+		{twitter + og + meta, "meta"},
+		{twitter + og, "og"},
+		{twitter, "twitter"},
+		{`<meta name="description" content="">` + twitter, "twitter"},
+		{``, ""},
+	}
+	for _, tt := range tests {
+		if got := metadataOf(t, tt.head).Description; got != tt.want {
+			t.Errorf("%s: description = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestMetadataLangFallback(t *testing.T) {
+	base, _ := url.Parse("https://example.com/")
+	doc := parseHTML(t, `<html><head><meta http-equiv="Content-Language" content="de-AT, en"></head></html>`)
+	if got := extractMetadata(doc, base).Lang; got != "de-AT" {
+		t.Errorf("lang = %q, want de-AT", got)
+	}
+}
+
+func TestMetadataJSONLD(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want []any
+	}{
+		{
+			name: "object",
+			head: `<script type="application/ld+json">{"@type": "Article", "name": "A"}</script>`,
+			want: []any{map[string]any{"@type": "Article", "name": "A"}},
+		},
+		{
+			name: "type parameters and case",
+			head: `<script type="Application/LD+JSON; charset=utf-8">{"n": 1}</script>`,
+			want: []any{map[string]any{"n": 1.0}},
+		},
+		{
+			name: "wrapped",
+			head: `<script type="application/ld+json"><!-- {"n": 1} --></script>
+<script type="application/ld+json">//<![CDATA[
+{"n": 2};
+//]]></script>`,
+			want: []any{map[string]any{"n": 1.0}, map[string]any{"n": 2.0}},
+		},
+		{
+			name: "top-level array",
+			head: `<script type="application/ld+json">[{"n": 1}, {"n": 2}]</script>`,
+			want: []any{map[string]any{"n": 1.0}, map[string]any{"n": 2.0}},
+		},
+		{
+			name: "graph",
+			head: `<script type="application/ld+json">{"@context": "https://schema.org", "@graph": [
+{"@type": "WebSite"}, {"@type": "WebPage", "@context": "https://other.org"}]}</script>`,
+			want: []any{
+				map[string]any{"@context": "https://schema.org", "@type": "WebSite"},
+				map[string]any{"@context": "https://other.org", "@type": "WebPage"},
+			},
+		},
+		{
+			name: "invalid blocks skipped",
+			head: `<script type="application/ld+json">{"n": </script>
+<script type="application/ld+json"></script>
+<script type="application/ld+json">{"n": 1}</script>
+<script type="application/json">{"n": 2}</script>`,
+			want: []any{map[string]any{"n": 1.0}},
+		},
+		{
+			name: "oversized block skipped",
+			head: `<script type="application/ld+json">{"text": "` + strings.Repeat("x", maxJSONLDBytes) + `"}</script>
+<script type="application/ld+json">{"n": 1}</script>`,
+			want: []any{map[string]any{"n": 1.0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := metadataOf(t, tt.head).JSONLD; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetadataCaps(t *testing.T) {
+	var head strings.Builder
+	for i := 0; i < maxPropertyValues+5; i++ {
+		fmt.Fprintf(&head, `<meta property="og:image" content="/%d.png">`, i)
+	}
+	head.WriteString(`<script type="application/ld+json">[`)
+	for i := 0; i < maxJSONLDItems+5; i++ {
+		if i > 0 {
+			head.WriteString(",")
+		}
+		fmt.Fprintf(&head, `{"n": %d}`, i)
+	}
+	head.WriteString(`]</script>`)
+
+	md := metadataOf(t, head.String())
+	if n := len(md.OpenGraph["og:image"]); n != maxPropertyValues {
+		t.Errorf("og:image values = %d, want %d", n, maxPropertyValues)
+	}
+	if n := len(md.JSONLD); n != maxJSONLDItems {
+		t.Errorf("JSON-LD items = %d, want %d", n, maxJSONLDItems)
+	}
+	if first := md.JSONLD[0]; !reflect.DeepEqual(first, map[string]any{"n": 0.0}) {
+		t.Errorf("first JSON-LD item = %v, want the first in the document", first)
+	}
+}
@@ -2,6 +2,7 @@ package crawl
 
 import (
 	"bytes"
+	"go-crawler/internal/model"
 	"net/url"
 	"strings"
 
@@ -9,9 +10,28 @@ import (
 )
 
 type ParsedPage struct {
-	Title       string
-	Links       []string
+	Title string
+	// Links are the page's http(s) links from every link source, resolved against
+	// <base href>; the crawl session picks the sources a job follows.
+	Links []model.Link
+	// TextContent is the visible text, blocks separated by blank lines.
 	TextContent string
+	// Blocks is the visible text with its headings, paragraphs and list items kept apart.
+	Blocks []TextBlock
+	// MainContent is the text of the main article body, without navigation, sidebars
+	// and footers; empty when no part of the page reads like an article.
+	MainContent string
+	// Robots holds the page's <meta name="robots"> directives.
+	Robots RobotsDirectives
+	// Canonical is the page's <link rel="canonical"> URL, or "" if it declares none.
+	Canonical string
+	// Metadata is the page's description, keywords, language, Open Graph and Twitter
+	// properties, hreflang alternates and JSON-LD.
+	Metadata model.PageMetadata
+	// Tables are the page's tables with spans resolved, nested tables listed separately.
+	Tables []model.Table
+
+	doc *html.Node // parse tree, for the job's extraction rules
 }
 
 func ParsePage(baseURL string, body []byte) (*ParsedPage, error) {
@@ -26,11 +46,7 @@ func ParsePage(baseURL string, body []byte) (*ParsedPage, error) {
 		return nil, err
 	}
 
-	var (
-		title       string
-		links       []string
-		textContent string
-	)
+	var title string
 
 	var walker func(*html.Node)
 	walker = func(n *html.Node) {
@@ -42,40 +58,6 @@ func ParsePage(baseURL string, body []byte) (*ParsedPage, error) {
 			}
 		}
 
-		// Link extraction
-		if n.Type == html.ElementNode && n.Data == "a" {
-			for _, attr := range n.Attr {
-				if attr.Key == "href" {
-					href := strings.TrimSpace(attr.Val)
-					if href == "" {
-						continue
-					}
-
-					ref, err := url.Parse(href)
-					if err != nil {
-						continue
-					}
-
-					absolute := base.ResolveReference(ref)
-
-					if absolute.Scheme == "http" || absolute.Scheme == "https" {
-						links = append(links, absolute.String())
-					}
-				}
-			}
-		}
-
-		// Text content extraction
-		if n.Type == html.TextNode {
-			s := strings.TrimSpace(n.Data)
-			if s != "" {
-				if textContent != "" {
-					textContent += " "
-				}
-				textContent += s
-			}
-		}
-
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
 			walker(c)
 		}
@@ -83,9 +65,23 @@ func ParsePage(baseURL string, body []byte) (*ParsedPage, error) {
 
 	walker(doc)
 
+	// Link extraction
+	base = documentBase(doc, base)
+	links := extractLinks(doc, base)
+
+	// Text content extraction
+	blocks := extractText(doc)
+
 	return &ParsedPage{
 		Title:       title,
 		Links:       links,
-		TextContent: strings.TrimSpace(textContent),
+		TextContent: joinBlocks(blocks),
+		Blocks:      blocks,
+		MainContent: joinBlocks(extractMainContent(doc)),
+		Robots:      metaRobots(doc),
+		Canonical:   extractCanonical(doc, base),
+		Metadata:    extractMetadata(doc, base),
+		Tables:      extractTables(doc),
+		doc:         doc,
 	}, nil
 }
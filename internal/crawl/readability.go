@@ -0,0 +1,207 @@
+package crawl
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Main-content extraction in the style of Mozilla's Readability: paragraph-like
+// blocks score their parent and grandparent by how much prose they hold, container
+// scores are discounted by link density, and the best container plus its related
+// siblings form the article. Navigation, footers, cookie banners and the like score
+// low because they are short and mostly links, and are dropped from the result.
+
+const (
+	minParagraphLen = 25 // shorter blocks (captions, buttons, menu items) do not score
+	minSiblingScore = 10
+)
+
+var (
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeHint = regexp.MustCompile(`(?i)-ad-|banner|breadcrumb|combx|comment|com-|consent|contact|cookie|foot|gdpr|masthead|menu|meta|modal|nav|outbrain|popup|promo|related|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|tags|tool|widget`)
+)
+
+// boilerplateElements are dropped from the main content even inside the chosen container.
+var boilerplateElements = map[string]bool{"nav": true, "aside": true, "footer": true, "form": true}
+
+// extractMainContent returns the blocks of doc's main content, or nil when no
+// container holds enough prose to tell (e.g. a page that is only a link list).
+func extractMainContent(doc *html.Node) []TextBlock {
+	scores := make(map[*html.Node]float64)
+	var order []*html.Node // candidates in first-scored order, for deterministic ties
+	addScore := func(n *html.Node, s float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = baseScore(n)
+			order = append(order, n)
+		}
+		scores[n] += s
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (skippedElements[n.Data] || isHidden(n)) {
+			return
+		}
+		if n.Type == html.ElementNode && isParagraphLike(n) {
+			if length := textLength(n, false); length >= minParagraphLen {
+				s := 1 + float64(strings.Count(innerText(n), ",")) + min(float64(length)/100, 3)
+				addScore(n.Parent, s)
+				if n.Parent != nil {
+					addScore(n.Parent.Parent, s/2)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var top *html.Node
+	var topScore float64
+	for _, n := range order {
+		scores[n] *= 1 - linkDensity(n)
+		if top == nil || scores[n] > topScore {
+			top, topScore = n, scores[n]
+		}
+	}
+	if top == nil || topScore <= 0 {
+		return nil
+	}
+
+	// Siblings sharing the top container's parent often hold the rest of the article
+	// (e.g. split into several divs); keep those that score well or read like prose.
+	nodes := []*html.Node{top}
+	if top.Parent != nil {
+		nodes = nodes[:0]
+		threshold := max(minSiblingScore, topScore*0.2)
+		for sib := top.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+			if sib == top {
+				nodes = append(nodes, sib)
+				continue
+			}
+			if sib.Type != html.ElementNode {
+				continue
+			}
+			if s, ok := scores[sib]; ok && s >= threshold {
+				nodes = append(nodes, sib)
+				continue
+			}
+			if sib.Data == "p" && textLength(sib, false) > 80 && linkDensity(sib) < 0.25 {
+				nodes = append(nodes, sib)
+			}
+		}
+	}
+
+	x := &textExtractor{skip: isBoilerplate}
+	for _, n := range nodes {
+		x.walk(n, BlockParagraph, 0)
+		x.flush()
+	}
+	return x.blocks
+}
+
+// baseScore is a container's starting score from its tag and its class and id hints.
+func baseScore(n *html.Node) float64 {
+	var s float64
+	switch n.Data {
+	case "article", "main":
+		s = 10
+	case "div":
+		s = 5
+	case "pre", "td", "blockquote":
+		s = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		s = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		s = -5
+	}
+	return s + classWeight(n)
+}
+
+// classWeight scores class and id attributes that hint at content or boilerplate.
+func classWeight(n *html.Node) float64 {
+	var w float64
+	for _, attr := range n.Attr {
+		if attr.Key != "class" && attr.Key != "id" {
+			continue
+		}
+		if negativeHint.MatchString(attr.Val) {
+			w -= 25
+		}
+		if positiveHint.MatchString(attr.Val) {
+			w += 25
+		}
+	}
+	return w
+}
+
+// isBoilerplate reports elements left out of the main content.
+func isBoilerplate(n *html.Node) bool {
+	return boilerplateElements[n.Data] || classWeight(n) < 0
+}
+
+// isParagraphLike reports blocks that score their ancestors: paragraphs, cells, quotes
+// and preformatted text, plus divs and sections used as paragraphs (no block children).
+func isParagraphLike(n *html.Node) bool {
+	switch n.Data {
+	case "p", "pre", "td", "blockquote":
+		return true
+	case "div", "section":
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && blockElements[c.Data] {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// linkDensity is the share of n's visible text that sits inside links.
+func linkDensity(n *html.Node) float64 {
+	total := textLength(n, false)
+	if total == 0 {
+		return 0
+	}
+	return float64(textLength(n, true)) / float64(total)
+}
+
+// textLength counts the visible, whitespace-collapsed characters under n; with
+// linksOnly, only those inside <a> elements.
+func textLength(n *html.Node, linksOnly bool) int {
+	var length int
+	var walk func(*html.Node, bool)
+	walk = func(n *html.Node, inLink bool) {
+		switch n.Type {
+		case html.TextNode:
+			if !linksOnly || inLink {
+				length += len(strings.Join(strings.Fields(n.Data), " "))
+			}
+			return
+		case html.ElementNode:
+			if skippedElements[n.Data] || isHidden(n) {
+				return
+			}
+			inLink = inLink || n.Data == "a"
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c, inLink)
+		}
+	}
+	walk(n, false)
+	return length
+}
+
+// innerText is n's visible text with whitespace collapsed.
+func innerText(n *html.Node) string {
+	x := &textExtractor{}
+	x.walk(n, BlockParagraph, 0)
+	x.flush()
+	return joinBlocks(x.blocks)
+}
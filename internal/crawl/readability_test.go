@@ -0,0 +1,69 @@
+package crawl
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const (
+	prose1 = "The first paragraph of the article, long enough to count as prose, with a comma."
+	prose2 = "The second paragraph of the article goes on, and on, about the same subject."
+)
+
+func TestExtractMainContent(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string // block texts
+	}{
+		{
+			name: "link density",
+			body: `<div><p>` + prose1 + `</p><p>` + prose2 + `</p></div>
+<div><p><a href="/1">A link with a long enough anchor text</a>, <a href="/2">and another long anchor text</a></p>
+<p><a href="/3">Yet another link whose anchor text is long</a>, <a href="/4">and one more long anchor</a></p></div>`,
+			want: []string{prose1, prose2},
+		},
+		{
+			name: "negative class",
+			body: `<div class="comments"><p>` + prose2 + `</p><p>` + prose2 + `</p><p>` + prose2 + `</p></div>
+<div><p>` + prose1 + `</p></div>`,
+			want: []string{prose1},
+		},
+		{
+			name: "positive id",
+			body: `<div><p>` + prose1 + `</p></div>
+<div id="main"><p>` + prose2 + `</p></div>`,
+			want: []string{prose2},
+		},
+		{
+			name: "boilerplate inside the container",
+			body: `<article><nav>Home, About, Contact, and a few more links</nav><h1>Title</h1>
+<p>` + prose1 + `</p><div class="share">Share this on every network you know of</div>
+<p>` + prose2 + `</p><footer>Copyright, all rights reserved, forever</footer></article>`,
+			want: []string{"Title", prose1, prose2},
+		},
+		{
+			name: "prose directly in body",
+			body: `<p>` + prose1 + `</p><p>` + prose2 + `</p>`,
+			want: []string{prose1, prose2},
+		},
+		{
+			name: "no prose",
+			body: `<ul><li><a href="/a">Short</a></li><li><a href="/b">Links</a></li></ul>`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseHTML(t, "<html><head><title>T</title></head><body>"+tt.body+"</body></html>")
+			var got []string
+			for _, b := range extractMainContent(doc) {
+				got = append(got, b.Text)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
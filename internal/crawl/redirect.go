@@ -0,0 +1,62 @@
+package crawl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-crawler/internal/model"
+	"net/http"
+)
+
+// maxRedirects matches net/http's default limit.
+const maxRedirects = 10
+
+// redirectTrace collects the hops of one fetch. The engine's CheckRedirect hook finds it
+// in the request context, so a single shared http.Client serves every job.
+type redirectTrace struct {
+	hops []model.RedirectHop
+	// check vets each redirect target before it is requested; nil allows all.
+	check func(ctx context.Context, target string) *redirectRejected
+}
+
+type redirectTraceKey struct{}
+
+func withRedirectTrace(ctx context.Context, trace *redirectTrace) context.Context {
+	return context.WithValue(ctx, redirectTraceKey{}, trace)
+}
+
+// redirectRejected stops a redirect to a URL the job must not fetch.
+type redirectRejected struct {
+	url    string
+	reason model.SkipReason
+	rule   string
+}
+
+func (e *redirectRejected) Error() string {
+	return fmt.Sprintf("redirect to %s rejected: %s", e.url, e.reason)
+}
+
+var errTooManyRedirects = errors.New("stopped after 10 redirects")
+
+// checkRedirect is the engine client's CheckRedirect hook. It records the hop in the
+// request's redirectTrace and lets the trace veto the target.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errTooManyRedirects
+	}
+	trace, _ := req.Context().Value(redirectTraceKey{}).(*redirectTrace)
+	if trace == nil {
+		return nil
+	}
+	hop := model.RedirectHop{From: via[len(via)-1].URL.String(), To: req.URL.String()}
+	if req.Response != nil {
+		hop.StatusCode = req.Response.StatusCode
+	}
+	trace.hops = append(trace.hops, hop)
+	if trace.check != nil {
+		if rejected := trace.check(req.Context(), req.URL.String()); rejected != nil {
+			return rejected
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package crawl
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"go-crawler/internal/model"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Retry policy for transient fetch failures. Backoff doubles per attempt from
+// retryBaseDelay up to retryMaxDelay, with jitter so retries of many URLs spread out.
+const (
+	defaultMaxAttempts = 3
+	retryBaseDelay     = time.Second
+	retryMaxDelay      = 2 * time.Minute
+	// maxRetryAfter is the longest Retry-After honoured; a server asking for more gets
+	// no retry rather than holding the job open. A retry wait never delays a cancel:
+	// the task is persisted as QUEUED with its next attempt time before it waits, so the
+	// scheduler drains it at once and a resumed crawl keeps the wait.
+	maxRetryAfter = 10 * time.Minute
+)
+
+// fetchError is a failed fetch. retryable marks failures worth another attempt;
+// retryAfter is the server's Retry-After, if it sent one. skip, when set, is the
+// reason the URL is recorded as skipped.
+type fetchError struct {
+	err        error
+	class      model.FetchErrorClass
+	skip       model.SkipReason
+	status     int // 0 when no response was received
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *fetchError) Error() string {
+	if e.status != 0 {
+		return fmt.Sprintf("HTTP %d", e.status)
+	}
+	return e.err.Error()
+}
+
+func (e *fetchError) Unwrap() error { return e.err }
+
+// errorClass returns the fetch log class for an error returned by Engine.fetch.
+func errorClass(err error) model.FetchErrorClass {
+	var fe *fetchError
+	if errors.As(err, &fe) {
+		return fe.class
+	}
+	return classifyTransport(err)
+}
+
+// statusError builds the fetchError for a non-200 response. 429 and 5xx are retried.
+func statusError(resp *http.Response) *fetchError {
+	code := resp.StatusCode
+	fe := &fetchError{
+		class:     model.FetchErrorHTTPStatus,
+		status:    code,
+		retryable: code == http.StatusTooManyRequests || code >= 500,
+	}
+	if fe.retryable {
+		fe.retryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+	return fe
+}
+
+// transportError builds the fetchError for a request that got no (complete) response.
+// Timeouts and dropped connections are retried; anything else (DNS failure, TLS
+// errors, refused connections, bad URLs) is not.
+func transportError(err error) *fetchError {
+	class := classifyTransport(err)
+	return &fetchError{
+		err:       err,
+		class:     class,
+		retryable: class == model.FetchErrorTimeout || class == model.FetchErrorConnReset,
+	}
+}
+
+func classifyTransport(err error) model.FetchErrorClass {
+	var (
+		netErr     net.Error
+		dnsErr     *net.DNSError
+		certErr    *tls.CertificateVerificationError
+		headerErr  tls.RecordHeaderError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+		rejected   *redirectRejected
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return model.FetchErrorCancelled
+	case errors.As(err, &rejected):
+		return model.FetchErrorRedirect
+	case errors.Is(err, errTooManyRedirects):
+		return model.FetchErrorTooManyRedirects
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return model.FetchErrorTimeout
+	case errors.As(err, &dnsErr):
+		return model.FetchErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &headerErr), errors.As(err, &unknownCA),
+		errors.As(err, &hostErr), errors.As(err, &invalidErr):
+		return model.FetchErrorTLS
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return model.FetchErrorConnReset
+	default:
+		return model.FetchErrorNetwork
+	}
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or HTTP-date form.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := at.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// backoff returns the delay before retry number attempt (1 for the first retry):
+// retryBaseDelay doubled per attempt, capped at retryMaxDelay, with "equal jitter"
+// (half fixed, half random).
+func backoff(attempt int) time.Duration {
+	d := retryMaxDelay
+	if attempt < 16 {
+		d = min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	}
+	return d/2 + rand.N(d/2+1)
+}
@@ -0,0 +1,183 @@
+package crawl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-crawler/internal/model"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	for attempt := 1; attempt <= 20; attempt++ {
+		full := retryMaxDelay
+		if attempt < 16 {
+			full = min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+		}
+		for i := 0; i < 100; i++ {
+			d := backoff(attempt)
+			if d < full/2 || d > full {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, full/2, full)
+			}
+		}
+	}
+	if got := backoff(1); got > retryBaseDelay {
+		t.Errorf("first retry waits %v, want at most %v", got, retryBaseDelay)
+	}
+	if got := backoff(100); got < retryMaxDelay/2 {
+		t.Errorf("backoff(100) = %v, want the capped delay", got)
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		seen[backoff(3)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("50 backoffs all returned %v, want jitter", backoff(3))
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{" 5 ", 5 * time.Second, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"1.5", 0, false},
+		{"Fri, 02 Jan 2026 15:05:05 GMT", time.Minute, true},       // IMF-fixdate
+		{"Friday, 02-Jan-26 15:14:05 GMT", 10 * time.Minute, true}, // RFC 850
+		{"Fri Jan  2 15:04:35 2026", 30 * time.Second, true},       // ANSI C asctime
+		{"Fri, 02 Jan 2026 15:00:00 GMT", 0, true},                 // in the past: retry now
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestStatusErrorRetryable(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		retryable  bool
+		wantAfter  time.Duration
+	}{
+		{http.StatusTooManyRequests, "30", true, 30 * time.Second},
+		{http.StatusServiceUnavailable, "", true, 0},
+		{http.StatusInternalServerError, "7", true, 7 * time.Second},
+		{http.StatusNotFound, "30", false, 0},
+		{http.StatusForbidden, "", false, 0},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		if tt.retryAfter != "" {
+			resp.Header.Set("Retry-After", tt.retryAfter)
+		}
+		fe := statusError(resp)
+		if fe.retryable != tt.retryable || fe.retryAfter != tt.wantAfter {
+			t.Errorf("statusError(%d, Retry-After %q) = retryable %v after %v; want %v after %v",
+				tt.status, tt.retryAfter, fe.retryable, fe.retryAfter, tt.retryable, tt.wantAfter)
+		}
+		if fe.class != model.FetchErrorHTTPStatus {
+			t.Errorf("statusError(%d) class = %s, want %s", tt.status, fe.class, model.FetchErrorHTTPStatus)
+		}
+	}
+}
+
+func TestSessionRetry(t *testing.T) {
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "retry", Input: model.CrawlInput{StartURL: "http://example.com/", MaxAttempts: 3}}
+	sess, err := newCrawlSession(job, store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	task := &model.URLTask{URL: "http://example.com/a", Depth: 1}
+	store.AddFrontierTask(ctx, job.ID, task)
+
+	before := time.Now()
+	next, ok := sess.retry(ctx, task, time.Minute)
+	if !ok {
+		t.Fatal("first retry refused")
+	}
+	if next.Attempt != 1 || next.URL != task.URL || next.Depth != task.Depth {
+		t.Errorf("retry task = %+v", next)
+	}
+	if wait := next.NotBefore.Sub(before); wait < time.Minute {
+		t.Errorf("retry waits %v, want at least Retry-After (1m)", wait)
+	}
+	if got := <-sess.urlQueue; got != next {
+		t.Errorf("queued %+v, want %+v", got, next)
+	}
+	if e := store.frontier[job.ID][0]; e.State != model.FrontierQueued || e.Attempts != 1 || !e.NextAttemptAt.Equal(next.NotBefore) {
+		t.Errorf("frontier entry = %+v, want QUEUED attempt 1 at %v", e, next.NotBefore)
+	}
+
+	if _, ok := sess.retry(ctx, next, maxRetryAfter+time.Second); ok {
+		t.Error("retry accepted a Retry-After over maxRetryAfter")
+	}
+	if _, ok := sess.retry(ctx, &model.URLTask{URL: task.URL, Attempt: 2}, 0); ok {
+		t.Error("retry accepted a task past MaxAttempts")
+	}
+}
+
+// TestCancelDuringRetryWait cancels a job while its only host is paused by
+// Retry-After. Start must return at once and leave the task QUEUED for a resume.
+func TestCancelDuringRetryWait(t *testing.T) {
+	fetched := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "300")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		select {
+		case fetched <- struct{}{}:
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	store := newMemStore()
+	job := &model.CrawlJob{ID: "cancel", Input: model.CrawlInput{
+		StartURL:     srv.URL + "/",
+		MaxDepth:     1,
+		MaxPages:     10,
+		IgnoreRobots: true,
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- newTestEngine(2, store).Start(ctx, job) }()
+
+	<-fetched
+	time.Sleep(50 * time.Millisecond) // let the retry reach the scheduler
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Start = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start did not return after cancel while a retry was waiting")
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	e := store.entry(job.ID, srv.URL+"/")
+	if e == nil || e.State != model.FrontierQueued || e.Attempts != 1 || e.NextAttemptAt.IsZero() {
+		t.Errorf("frontier entry = %+v, want QUEUED with attempt 1 and a next attempt time", e)
+	}
+	if n := len(store.fetches[job.ID]); n != 1 {
+		t.Errorf("%d fetches logged, want 1", n)
+	}
+}
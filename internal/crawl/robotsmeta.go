@@ -0,0 +1,110 @@
+package crawl
+
+import (
+	"fmt"
+	"go-crawler/internal/model"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// RobotsDirectives are the page-level robots directives that affect the crawl, from
+// <meta name="robots"> and <meta name="go-crawler"> tags or X-Robots-Tag headers.
+type RobotsDirectives struct {
+	NoIndex  bool // do not store or index the page
+	NoFollow bool // do not follow the page's links
+}
+
+// merge returns the union of d and o: a directive from either source applies.
+func (d RobotsDirectives) merge(o RobotsDirectives) RobotsDirectives {
+	return RobotsDirectives{NoIndex: d.NoIndex || o.NoIndex, NoFollow: d.NoFollow || o.NoFollow}
+}
+
+// parseRobotsDirectives reads a comma-separated directive list such as "noindex, follow".
+// "none" means noindex and nofollow; unknown directives are ignored.
+func parseRobotsDirectives(value string) RobotsDirectives {
+	var d RobotsDirectives
+	for _, token := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(token)) {
+		case "noindex":
+			d.NoIndex = true
+		case "nofollow":
+			d.NoFollow = true
+		case "none":
+			d.NoIndex, d.NoFollow = true, true
+		}
+	}
+	return d
+}
+
+// metaRobots reads the <meta name="robots"> tags of doc and those addressed to this
+// crawler by its robots.txt product token.
+func metaRobots(doc *html.Node) RobotsDirectives {
+	var d RobotsDirectives
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "meta" {
+			name := strings.ToLower(strings.TrimSpace(attrValue(n, "name")))
+			if name == "robots" || name == robotsUserAgent {
+				d = d.merge(parseRobotsDirectives(attrValue(n, "content")))
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return d
+}
+
+// xRobotsDirectives are X-Robots-Tag directives that take a value after a colon, so a
+// colon after them does not name a user agent.
+var xRobotsDirectives = map[string]bool{
+	"unavailable_after": true, "max-snippet": true, "max-image-preview": true, "max-video-preview": true,
+}
+
+// xRobotsTag reads the X-Robots-Tag headers of a response. A header may be addressed
+// to one crawler ("googlebot: noindex"); only those for all crawlers or for this
+// crawler's product token apply.
+func xRobotsTag(header http.Header) RobotsDirectives {
+	var d RobotsDirectives
+	for _, value := range header.Values("X-Robots-Tag") {
+		if agent, rest, ok := strings.Cut(value, ":"); ok {
+			agent = strings.ToLower(strings.TrimSpace(agent))
+			if !xRobotsDirectives[agent] && !strings.Contains(agent, ",") {
+				if agent != robotsUserAgent {
+					continue
+				}
+				value = rest
+			}
+		}
+		d = d.merge(parseRobotsDirectives(value))
+	}
+	return d
+}
+
+// robotsMetaPolicy is which directives a job honors.
+type robotsMetaPolicy struct {
+	noIndex  bool
+	noFollow bool
+}
+
+func newRobotsMetaPolicy(input model.CrawlInput) (robotsMetaPolicy, error) {
+	switch input.RobotsMeta {
+	case "", model.RobotsMetaRespect:
+		return robotsMetaPolicy{noIndex: true, noFollow: true}, nil
+	case model.RobotsMetaIgnoreNoIndex:
+		return robotsMetaPolicy{noFollow: true}, nil
+	case model.RobotsMetaIgnoreNoFollow:
+		return robotsMetaPolicy{noIndex: true}, nil
+	case model.RobotsMetaIgnore:
+		return robotsMetaPolicy{}, nil
+	}
+	return robotsMetaPolicy{}, fmt.Errorf("unknown robots meta policy %q", input.RobotsMeta)
+}
+
+// apply drops the directives the job ignores.
+func (p robotsMetaPolicy) apply(d RobotsDirectives) RobotsDirectives {
+	return RobotsDirectives{NoIndex: d.NoIndex && p.noIndex, NoFollow: d.NoFollow && p.noFollow}
+}
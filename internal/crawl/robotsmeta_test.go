@@ -0,0 +1,82 @@
+package crawl
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseRobotsDirectives(t *testing.T) {
+	tests := []struct {
+		value string
+		want  RobotsDirectives
+	}{
+		{"", RobotsDirectives{}},
+		{"index, follow", RobotsDirectives{}},
+		{"noindex", RobotsDirectives{NoIndex: true}},
+		{" NOFOLLOW ", RobotsDirectives{NoFollow: true}},
+		{"noindex,nofollow", RobotsDirectives{NoIndex: true, NoFollow: true}},
+		{"none", RobotsDirectives{NoIndex: true, NoFollow: true}},
+		{"None, noarchive", RobotsDirectives{NoIndex: true, NoFollow: true}},
+		{"noarchive, nosnippet", RobotsDirectives{}},
+	}
+	for _, tt := range tests {
+		if got := parseRobotsDirectives(tt.value); got != tt.want {
+			t.Errorf("parseRobotsDirectives(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMetaRobots(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want RobotsDirectives
+	}{
+		{"none", ``, RobotsDirectives{}},
+		{"robots", `<meta name="robots" content="noindex">`, RobotsDirectives{NoIndex: true}},
+		{"name case and spaces", `<meta name=" ROBOTS " content="nofollow">`, RobotsDirectives{NoFollow: true}},
+		{"this crawler", `<meta name="go-crawler" content="noindex">`, RobotsDirectives{NoIndex: true}},
+		{"other crawler", `<meta name="googlebot" content="noindex, nofollow">`, RobotsDirectives{}},
+		{"content none", `<meta name="robots" content="none">`, RobotsDirectives{NoIndex: true, NoFollow: true}},
+		{"merged", `<meta name="robots" content="noindex"><meta name="go-crawler" content="nofollow">`,
+			RobotsDirectives{NoIndex: true, NoFollow: true}},
+		{"not a name", `<meta property="robots" content="noindex">`, RobotsDirectives{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseHTML(t, "<html><head>"+tt.head+"</head><body></body></html>")
+			if got := metaRobots(doc); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestXRobotsTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   RobotsDirectives
+	}{
+		{"absent", nil, RobotsDirectives{}},
+		{"all crawlers", []string{"noindex"}, RobotsDirectives{NoIndex: true}},
+		{"none", []string{"none"}, RobotsDirectives{NoIndex: true, NoFollow: true}},
+		{"this crawler", []string{"go-crawler: nofollow"}, RobotsDirectives{NoFollow: true}},
+		{"agent case", []string{"Go-Crawler: noindex"}, RobotsDirectives{NoIndex: true}},
+		{"other crawler", []string{"googlebot: noindex, nofollow"}, RobotsDirectives{}},
+		{"valued directive", []string{"unavailable_after: 25 Jun 2010 15:00:00 PST"}, RobotsDirectives{}},
+		{"directive list with a value", []string{"noindex, max-snippet: 20"}, RobotsDirectives{NoIndex: true}},
+		{"several headers", []string{"googlebot: noindex", "nofollow"}, RobotsDirectives{NoFollow: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, v := range tt.values {
+				header.Add("X-Robots-Tag", v)
+			}
+			if got := xRobotsTag(header); got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
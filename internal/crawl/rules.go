@@ -0,0 +1,107 @@
+package crawl
+
+import (
+	"fmt"
+	"go-crawler/internal/model"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlRules is a job's compiled include/exclude rules. Rules are tried in order and
+// the first match decides. A link matching no rule is allowed, unless the job has
+// at least one INCLUDE rule, in which case only included links are followed.
+type urlRules struct {
+	rules      []compiledRule
+	hasInclude bool
+}
+
+type compiledRule struct {
+	include bool
+	re      *regexp.Regexp
+	desc    string // shown in page stats, e.g. `#2 EXCLUDE GLOB /docs/archive/**`
+}
+
+func newURLRules(rules []model.URLRule) (*urlRules, error) {
+	out := &urlRules{}
+	for i, r := range rules {
+		var include bool
+		switch r.Action {
+		case model.URLRuleInclude:
+			include = true
+			out.hasInclude = true
+		case model.URLRuleExclude:
+		default:
+			return nil, fmt.Errorf("url rule #%d: unknown action %q", i+1, r.Action)
+		}
+
+		syntax := r.Syntax
+		if syntax == "" {
+			syntax = model.URLRuleGlob
+		}
+		var expr string
+		switch syntax {
+		case model.URLRuleGlob:
+			expr = globToRegexp(r.Pattern)
+		case model.URLRuleRegex:
+			expr = r.Pattern
+		default:
+			return nil, fmt.Errorf("url rule #%d: unknown syntax %q", i+1, r.Syntax)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("url rule #%d: %w", i+1, err)
+		}
+		out.rules = append(out.rules, compiledRule{
+			include: include,
+			re:      re,
+			desc:    fmt.Sprintf("#%d %s %s %s", i+1, r.Action, syntax, r.Pattern),
+		})
+	}
+	return out, nil
+}
+
+// check reports whether u passes the rules. When it does not, rule describes the
+// rejecting rule, or is empty if the link was rejected for matching no INCLUDE rule.
+func (r *urlRules) check(u *url.URL) (allowed bool, rule string) {
+	target := ruleTarget(u)
+	for _, cr := range r.rules {
+		if cr.re.MatchString(target) {
+			return cr.include, cr.desc
+		}
+	}
+	return !r.hasInclude, ""
+}
+
+// ruleTarget is the part of a URL rules are matched against: the path plus "?query".
+func ruleTarget(u *url.URL) string {
+	target := u.EscapedPath()
+	if target == "" {
+		target = "/"
+	}
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target
+}
+
+// globToRegexp converts a glob to an anchored regexp. "**" matches anything,
+// "*" matches anything except "/", and every other character is literal.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		if glob[i] != '*' {
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			continue
+		}
+		if i+1 < len(glob) && glob[i+1] == '*' {
+			b.WriteString(".*")
+			i++
+			continue
+		}
+		b.WriteString("[^/]*")
+	}
+	b.WriteString("$")
+	return b.String()
+}
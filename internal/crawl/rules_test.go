@@ -0,0 +1,132 @@
+package crawl
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		match []string
+		miss  []string
+	}{
+		{"/docs", []string{"/docs"}, []string{"/docs/", "/docs/a", "/api/docs", "/docsx"}},
+		{"/docs/*", []string{"/docs/", "/docs/a", "/docs/a.html"}, []string{"/docs", "/docs/a/b", "/docs/a?x=1/2"}},
+		{"/docs/**", []string{"/docs/", "/docs/a", "/docs/a/b/c", "/docs/a?x=1"}, []string{"/docs", "/api/docs/a"}},
+		{"**/print", []string{"/print", "/a/b/print"}, []string{"/a/print/x"}},
+		{"/*/edit", []string{"/page/edit", "//edit"}, []string{"/a/b/edit", "/edit"}},
+		{"/img/*.png", []string{"/img/a.png", "/img/.png"}, []string{"/img/a.jpg", "/img/a/b.png", "/img/apng"}},
+		// "?" and other regexp metacharacters are literal: "?" starts the query.
+		{"/search?q=*", []string{"/search?q=go", "/search?q="}, []string{"/search", "/searchXq=go", "/search?q=a/b"}},
+		{"/a+b/(c)/[d]/$", []string{"/a+b/(c)/[d]/$"}, []string{"/aab/c/d/"}},
+		{"**", []string{"", "/", "/anything?at=all"}, nil},
+		{"*", []string{"", "abc"}, []string{"/", "a/b"}},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(globToRegexp(tt.glob))
+		for _, s := range tt.match {
+			if !re.MatchString(s) {
+				t.Errorf("glob %q does not match %q", tt.glob, s)
+			}
+		}
+		for _, s := range tt.miss {
+			if re.MatchString(s) {
+				t.Errorf("glob %q matches %q", tt.glob, s)
+			}
+		}
+	}
+}
+
+func TestURLRulesCheck(t *testing.T) {
+	include := func(p string) model.URLRule { return model.URLRule{Action: model.URLRuleInclude, Pattern: p} }
+	exclude := func(p string) model.URLRule { return model.URLRule{Action: model.URLRuleExclude, Pattern: p} }
+	tests := []struct {
+		name  string
+		rules []model.URLRule
+		url   string
+		want  bool
+		rule  string // the deciding rule's description; "" when no rule matched
+	}{
+		{"no rules", nil, "http://x.com/a", true, ""},
+		{"exclude only, no match", []model.URLRule{exclude("/private/**")}, "http://x.com/a", true, ""},
+		{"exclude only, match", []model.URLRule{exclude("/private/**")}, "http://x.com/private/a", false, "#1 EXCLUDE GLOB /private/**"},
+		{"include, match", []model.URLRule{include("/docs/**")}, "http://x.com/docs/a", true, "#1 INCLUDE GLOB /docs/**"},
+		{"include, no match", []model.URLRule{include("/docs/**")}, "http://x.com/blog/a", false, ""},
+		{"root path", []model.URLRule{include("/")}, "http://x.com", true, "#1 INCLUDE GLOB /"},
+		{
+			"exclude before include wins",
+			[]model.URLRule{exclude("/docs/archive/**"), include("/docs/**")},
+			"http://x.com/docs/archive/2019", false, "#1 EXCLUDE GLOB /docs/archive/**",
+		},
+		{
+			"exclude before include, other docs included",
+			[]model.URLRule{exclude("/docs/archive/**"), include("/docs/**")},
+			"http://x.com/docs/guide", true, "#2 INCLUDE GLOB /docs/**",
+		},
+		{
+			"first match wins: include before exclude",
+			[]model.URLRule{include("/docs/**"), exclude("/docs/archive/**")},
+			"http://x.com/docs/archive/2019", true, "#1 INCLUDE GLOB /docs/**",
+		},
+		{"query is matched", []model.URLRule{exclude("**?*sort=*")}, "http://x.com/list?page=2&sort=asc", false, "#1 EXCLUDE GLOB **?*sort=*"},
+		{"escaped path is matched", []model.URLRule{exclude("/a%20b")}, "http://x.com/a%20b", false, "#1 EXCLUDE GLOB /a%20b"},
+		{
+			"regex matches anywhere",
+			[]model.URLRule{{Action: model.URLRuleExclude, Syntax: model.URLRuleRegex, Pattern: `\.(pdf|zip)$`}},
+			"http://x.com/files/a.zip", false, `#1 EXCLUDE REGEX \.(pdf|zip)$`,
+		},
+	}
+	for _, tt := range tests {
+		rules, err := newURLRules(tt.rules)
+		if err != nil {
+			t.Errorf("%s: newURLRules: %v", tt.name, err)
+			continue
+		}
+		u, _ := url.Parse(tt.url)
+		if got, rule := rules.check(u); got != tt.want || rule != tt.rule {
+			t.Errorf("%s: check(%s) = %v, %q; want %v, %q", tt.name, tt.url, got, rule, tt.want, tt.rule)
+		}
+	}
+}
+
+func TestNewURLRulesErrors(t *testing.T) {
+	tests := []struct {
+		rule model.URLRule
+		want string
+	}{
+		{model.URLRule{Action: "ALLOW", Pattern: "/a"}, "unknown action"},
+		{model.URLRule{Action: model.URLRuleInclude, Syntax: "PCRE", Pattern: "/a"}, "unknown syntax"},
+		{model.URLRule{Action: model.URLRuleInclude, Syntax: model.URLRuleRegex, Pattern: "/a("}, "missing closing )"},
+	}
+	for _, tt := range tests {
+		rules := []model.URLRule{{Action: model.URLRuleExclude, Pattern: "/ok"}, tt.rule}
+		_, err := newURLRules(rules)
+		if err == nil || !strings.Contains(err.Error(), "url rule #2") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("newURLRules(%+v) = %v, want an error for rule #2 mentioning %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+// TestValidate checks that invalid input is rejected when a job is submitted.
+func TestValidate(t *testing.T) {
+	e := newTestEngine(1, newMemStore())
+	valid := model.CrawlInput{StartURL: "http://example.com/", URLRules: []model.URLRule{{Action: model.URLRuleInclude, Pattern: "/docs/**"}}}
+	if err := e.Validate(valid); err != nil {
+		t.Errorf("Validate(valid input) = %v", err)
+	}
+	for _, input := range []model.CrawlInput{
+		{StartURL: "http://exa mple.com/"},
+		{StartURL: "http://example.com/", URLRules: []model.URLRule{{Action: model.URLRuleInclude, Syntax: model.URLRuleRegex, Pattern: "[a-"}}},
+		{StartURL: "http://example.com/", Scope: "WORLD"},
+		{StartURL: "http://example.com/", ExtractionRules: []model.ExtractionRule{{Name: "x", Selector: "a["}}},
+	} {
+		if err := e.Validate(input); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want an error", input)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package crawl
+
+import (
+	"context"
+	"go-crawler/internal/model"
+	"net/url"
+	"time"
+)
+
+// defaultMaxPerHost is used when CrawlInput.MaxConcurrentPerHost is not set.
+const defaultMaxPerHost = 2
+
+// hostScheduler sits between urlQueue and the workers. It buffers queued tasks per host
+// and hands a task to a worker only when its host is below maxPerHost in-flight
+// requests and the minimum delay since the host's previous request has passed.
+// A host with a long backlog therefore cannot monopolise the workers or be hammered.
+type hostScheduler struct {
+	in       <-chan *model.URLTask // urlQueue; closed when the crawl has no work left
+	out      chan *model.URLTask   // tasks ready to be fetched, read by workers
+	released chan string           // host of each finished task, sent by workers
+
+	minDelay   time.Duration
+	maxPerHost int
+	// hostDelay returns an extra per-host delay (robots.txt Crawl-delay); may be nil.
+	hostDelay func(taskURL string) time.Duration
+
+	hosts map[string]*hostState
+	order []string // hosts in first-seen order, scanned round-robin
+	next  int
+}
+
+type hostState struct {
+	pending  []*model.URLTask
+	inFlight int
+	nextAt   time.Time // earliest time the next request may start
+}
+
+func newHostScheduler(in <-chan *model.URLTask, minDelay time.Duration, maxPerHost int, hostDelay func(string) time.Duration) *hostScheduler {
+	if maxPerHost <= 0 {
+		maxPerHost = defaultMaxPerHost
+	}
+	return &hostScheduler{
+		in:         in,
+		out:        make(chan *model.URLTask),
+		released:   make(chan string),
+		minDelay:   minDelay,
+		maxPerHost: maxPerHost,
+		hostDelay:  hostDelay,
+		hosts:      make(map[string]*hostState),
+	}
+}
+
+// taskHost returns the key tasks are grouped by: the URL's host including port.
+func taskHost(task *model.URLTask) string {
+	u, err := url.Parse(task.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// release tells the scheduler a worker has finished a task for host.
+// Must be called before the task's activeCount decrement, while run is still receiving.
+func (s *hostScheduler) release(host string) {
+	s.released <- host
+}
+
+// run dispatches tasks until in is closed, then closes out. Once ctx is cancelled,
+// limits and delays are ignored so workers can drain the remaining tasks immediately,
+// including tasks waiting out a host delay or a retry's NotBefore.
+func (s *hostScheduler) run(ctx context.Context) {
+	defer close(s.out)
+	cancelled := ctx.Done()
+	for {
+		var (
+			out   chan *model.URLTask
+			ready *model.URLTask
+			host  string
+			wake  <-chan time.Time
+		)
+		draining := ctx.Err() != nil
+		if draining {
+			cancelled = nil // stop selecting on a closed channel
+		}
+		host, wait := s.pick(draining)
+		if host != "" {
+			out = s.out
+			ready = s.hosts[host].pending[0]
+		} else if wait > 0 {
+			wake = time.After(wait)
+		}
+
+		select {
+		case task, ok := <-s.in:
+			if !ok {
+				return
+			}
+			s.add(task)
+		case out <- ready:
+			s.dispatched(host, draining)
+		case h := <-s.released:
+			s.hosts[h].inFlight--
+		case <-wake:
+		case <-cancelled:
+		}
+	}
+}
+
+// add queues task for its host. A retry (NotBefore set) also pauses the whole host
+// until then: a host answering 429, 5xx or timing out is likely overloaded, and
+// Retry-After applies to the server rather than to one URL.
+func (s *hostScheduler) add(task *model.URLTask) {
+	host := taskHost(task)
+	hs, ok := s.hosts[host]
+	if !ok {
+		hs = &hostState{}
+		s.hosts[host] = hs
+		s.order = append(s.order, host)
+	}
+	hs.pending = append(hs.pending, task)
+	if task.NotBefore.After(hs.nextAt) {
+		hs.nextAt = task.NotBefore
+	}
+}
+
+// pick returns the next host (round-robin) that may start a request now. If none can,
+// it returns how long until the earliest delayed host becomes ready (0 if none is waiting
+// on a delay).
+func (s *hostScheduler) pick(draining bool) (string, time.Duration) {
+	now := time.Now()
+	var wait time.Duration
+	for i := 0; i < len(s.order); i++ {
+		host := s.order[(s.next+i)%len(s.order)]
+		hs := s.hosts[host]
+		if len(hs.pending) == 0 {
+			continue
+		}
+		if draining {
+			s.next = (s.next + i + 1) % len(s.order)
+			return host, 0
+		}
+		if hs.inFlight >= s.maxPerHost {
+			continue
+		}
+		if d := hs.nextAt.Sub(now); d > 0 {
+			if wait == 0 || d < wait {
+				wait = d
+			}
+			continue
+		}
+		s.next = (s.next + i + 1) % len(s.order)
+		return host, 0
+	}
+	return "", wait
+}
+
+func (s *hostScheduler) dispatched(host string, draining bool) {
+	hs := s.hosts[host]
+	task := hs.pending[0]
+	hs.pending[0] = nil
+	hs.pending = hs.pending[1:]
+	hs.inFlight++
+	if draining {
+		return
+	}
+	delay := s.minDelay
+	if s.hostDelay != nil {
+		if d := s.hostDelay(task.URL); d > delay {
+			delay = d
+		}
+	}
+	if next := time.Now().Add(delay); next.After(hs.nextAt) {
+		hs.nextAt = next
+	}
+}
@@ -0,0 +1,103 @@
+package crawl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-crawler/internal/model"
+)
+
+// receive returns the next task the scheduler hands out, or fails after timeout.
+func receive(t *testing.T, s *hostScheduler, timeout time.Duration) *model.URLTask {
+	t.Helper()
+	select {
+	case task, ok := <-s.out:
+		if !ok {
+			t.Fatal("scheduler closed its output")
+		}
+		return task
+	case <-time.After(timeout):
+		t.Fatalf("no task within %v", timeout)
+		return nil
+	}
+}
+
+func TestSchedulerDelaysHost(t *testing.T) {
+	in := make(chan *model.URLTask, 10)
+	s := newHostScheduler(in, 100*time.Millisecond, 1, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.run(ctx)
+
+	in <- &model.URLTask{URL: "http://a.test/1"}
+	in <- &model.URLTask{URL: "http://a.test/2"}
+	in <- &model.URLTask{URL: "http://b.test/1"}
+
+	start := time.Now()
+	first := receive(t, s, time.Second)
+	second := receive(t, s, time.Second)
+	if taskHost(first) == taskHost(second) {
+		t.Errorf("got %s then %s; want the other host before the delay", first.URL, second.URL)
+	}
+	s.release(taskHost(first))
+	s.release(taskHost(second))
+	third := receive(t, s, time.Second)
+	if third.URL != "http://a.test/2" {
+		t.Fatalf("third task = %s, want http://a.test/2", third.URL)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("same host dispatched again after %v, want at least the 100ms delay", elapsed)
+	}
+	s.release(taskHost(third))
+	close(in)
+	if _, ok := <-s.out; ok {
+		t.Error("output still open after input closed")
+	}
+}
+
+func TestSchedulerCancelWhileHostDelayed(t *testing.T) {
+	tests := []struct {
+		name     string
+		minDelay time.Duration
+		second   *model.URLTask
+	}{
+		{"request delay", time.Hour, &model.URLTask{URL: "http://a.test/2"}},
+		{"retry not before", 0, &model.URLTask{URL: "http://a.test/2", Attempt: 1, NotBefore: time.Now().Add(time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := make(chan *model.URLTask, 10)
+			s := newHostScheduler(in, tt.minDelay, 1, nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go s.run(ctx)
+
+			in <- &model.URLTask{URL: "http://a.test/1"}
+			first := receive(t, s, time.Second)
+			in <- tt.second
+			s.release(taskHost(first))
+
+			select {
+			case task := <-s.out:
+				t.Fatalf("%s dispatched before its host delay", task.URL)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			cancel()
+			if task := receive(t, s, time.Second); task.URL != tt.second.URL {
+				t.Fatalf("drained %s, want %s", task.URL, tt.second.URL)
+			}
+			s.release("a.test")
+			close(in)
+			select {
+			case _, ok := <-s.out:
+				if ok {
+					t.Error("unexpected task after drain")
+				}
+			case <-time.After(time.Second):
+				t.Error("scheduler did not stop after input closed")
+			}
+		})
+	}
+}
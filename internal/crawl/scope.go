@@ -0,0 +1,105 @@
+package crawl
+
+import (
+	"fmt"
+	"go-crawler/internal/model"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// scope decides which discovered links belong to a job. Only http and https URLs are
+// ever in scope, and switching between the two is allowed in every mode.
+type scope struct {
+	mode     model.ScopeMode
+	seedHost string   // lowercased hostname of the start URL, without port
+	seedPort string   // explicit non-default port of the start URL, or ""
+	domains  []string // ScopeDomain: the seed's registrable domain; ScopeAllowedDomains: the list
+}
+
+// newScope builds the scope for input. An empty Scope falls back to SameDomainOnly:
+// true means the seed's registrable domain, false the seed's host, as before scopes
+// existed. UNRESTRICTED is only used when asked for.
+func newScope(input model.CrawlInput, seed *url.URL) (*scope, error) {
+	mode := input.Scope
+	if mode == "" {
+		mode = model.ScopeHost
+		if input.SameDomainOnly {
+			mode = model.ScopeDomain
+		}
+	}
+	s := &scope{
+		mode:     mode,
+		seedHost: hostname(seed),
+		seedPort: explicitPort(seed),
+	}
+	switch mode {
+	case model.ScopeHost, model.ScopeUnrestricted:
+	case model.ScopeDomain:
+		s.domains = []string{registrableDomain(s.seedHost)}
+	case model.ScopeAllowedDomains:
+		for _, d := range input.AllowedDomains {
+			d = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "*"), ".")
+			d = strings.TrimSuffix(d, ".")
+			if d != "" {
+				s.domains = append(s.domains, d)
+			}
+		}
+		if len(s.domains) == 0 {
+			return nil, fmt.Errorf("scope %s requires at least one entry in AllowedDomains", mode)
+		}
+	default:
+		return nil, fmt.Errorf("unknown scope %q", mode)
+	}
+	return s, nil
+}
+
+// allows reports whether u may be enqueued.
+func (s *scope) allows(u *url.URL) bool {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	host := hostname(u)
+	if host == "" {
+		return false
+	}
+	switch s.mode {
+	case model.ScopeHost:
+		// Same hostname and same origin port, where 80/443 on http/https count as "no port".
+		return host == s.seedHost && explicitPort(u) == s.seedPort
+	case model.ScopeDomain, model.ScopeAllowedDomains:
+		for _, d := range s.domains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// hostname returns u's lowercased hostname without port or trailing dot.
+func hostname(u *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// explicitPort returns u's port unless it is the default for its scheme.
+func explicitPort(u *url.URL) string {
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		return ""
+	}
+	return port
+}
+
+// registrableDomain returns the eTLD+1 of host (e.g. "example.co.uk" for
+// "www.example.co.uk"). Hosts without one, such as IPs or "localhost", are returned as is.
+func registrableDomain(host string) string {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
@@ -0,0 +1,115 @@
+package crawl
+
+import (
+	"net/url"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func TestScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   model.CrawlInput
+		seed    string
+		allowed []string
+		denied  []string
+	}{
+		{
+			name:    "empty scope is host",
+			seed:    "http://www.example.com/",
+			allowed: []string{"http://www.example.com/a", "https://www.example.com/a", "http://WWW.Example.com./a"},
+			denied:  []string{"http://example.com/", "http://blog.example.com/", "http://other.org/"},
+		},
+		{
+			name:    "SameDomainOnly is domain",
+			input:   model.CrawlInput{SameDomainOnly: true},
+			seed:    "http://www.example.co.uk/",
+			allowed: []string{"http://example.co.uk/", "http://blog.example.co.uk/", "https://a.b.example.co.uk/"},
+			denied:  []string{"http://other.co.uk/", "http://example.com/", "http://badexample.co.uk/"},
+		},
+		{
+			name:    "explicit scope beats SameDomainOnly",
+			input:   model.CrawlInput{SameDomainOnly: true, Scope: model.ScopeHost},
+			seed:    "http://www.example.com/",
+			allowed: []string{"http://www.example.com/a"},
+			denied:  []string{"http://blog.example.com/"},
+		},
+		{
+			name:    "host with default ports",
+			input:   model.CrawlInput{Scope: model.ScopeHost},
+			seed:    "https://example.com:443/",
+			allowed: []string{"https://example.com/a", "http://example.com/a", "http://example.com:80/a", "https://example.com:443/a"},
+			denied:  []string{"http://example.com:8080/a", "https://example.com:8443/a"},
+		},
+		{
+			name:    "host with a non-default port",
+			input:   model.CrawlInput{Scope: model.ScopeHost},
+			seed:    "http://localhost:8080/",
+			allowed: []string{"http://localhost:8080/a", "https://localhost:8080/a"},
+			denied:  []string{"http://localhost/a", "http://localhost:8081/a", "http://127.0.0.1:8080/a"},
+		},
+		{
+			name:    "domain ignores ports",
+			input:   model.CrawlInput{Scope: model.ScopeDomain},
+			seed:    "http://example.com:8080/",
+			allowed: []string{"http://example.com/", "https://www.example.com:9000/"},
+		},
+		{
+			name:    "domain of an IP is the IP",
+			input:   model.CrawlInput{Scope: model.ScopeDomain},
+			seed:    "http://127.0.0.1:8080/",
+			allowed: []string{"http://127.0.0.1/"},
+			denied:  []string{"http://127.0.0.2/"},
+		},
+		{
+			name: "allowed domains normalized",
+			input: model.CrawlInput{Scope: model.ScopeAllowedDomains, AllowedDomains: []string{
+				" Example.COM. ", "*.docs.org", ".static.net", "",
+			}},
+			seed:    "http://start.io/",
+			allowed: []string{"http://example.com/", "http://www.example.com/", "http://api.docs.org/", "http://docs.org/", "http://cdn.static.net/"},
+			denied:  []string{"http://start.io/", "http://notexample.com/", "http://org/"},
+		},
+		{
+			name:    "unrestricted",
+			input:   model.CrawlInput{Scope: model.ScopeUnrestricted},
+			seed:    "http://example.com/",
+			allowed: []string{"http://other.org/", "https://a.b.c:8443/"},
+			denied:  []string{"ftp://example.com/", "mailto:a@example.com", "http:///nohost"},
+		},
+	}
+	for _, tt := range tests {
+		seed, _ := url.Parse(tt.seed)
+		s, err := newScope(tt.input, seed)
+		if err != nil {
+			t.Errorf("%s: newScope: %v", tt.name, err)
+			continue
+		}
+		for _, raw := range tt.allowed {
+			u, _ := url.Parse(raw)
+			if !s.allows(u) {
+				t.Errorf("%s: %s denied, want allowed", tt.name, raw)
+			}
+		}
+		for _, raw := range tt.denied {
+			u, _ := url.Parse(raw)
+			if s.allows(u) {
+				t.Errorf("%s: %s allowed, want denied", tt.name, raw)
+			}
+		}
+	}
+}
+
+func TestNewScopeErrors(t *testing.T) {
+	seed, _ := url.Parse("http://example.com/")
+	for _, input := range []model.CrawlInput{
+		{Scope: model.ScopeAllowedDomains},
+		{Scope: model.ScopeAllowedDomains, AllowedDomains: []string{" ", "*.", "."}},
+		{Scope: "WORLD"},
+	} {
+		if _, err := newScope(input, seed); err == nil {
+			t.Errorf("newScope(%+v) succeeded, want an error", input)
+		}
+	}
+}
@@ -0,0 +1,422 @@
+package crawl
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// A small CSS selector engine for extraction rules. Supported: type and universal
+// selectors, #id, .class, attribute selectors ([a], [a=v], [a~=v], [a|=v], [a^=v],
+// [a$=v], [a*=v], optionally with an " i" flag), :first-child, :last-child,
+// :only-child, :nth-child(n), :not(compound), the descendant, child (>), adjacent
+// (+) and general sibling (~) combinators, and comma-separated groups.
+
+// selector is a compiled selector group; an element matches if any member matches.
+type selector []complexSelector
+
+// complexSelector is compounds joined by combinators; combinators[i] joins
+// compounds[i] and compounds[i+1] and is one of ' ', '>', '+' and '~'.
+type complexSelector struct {
+	compounds   []compoundSelector
+	combinators []byte
+}
+
+type compoundSelector struct {
+	tag     string // "" matches any element
+	id      string
+	classes []string
+	attrs   []attrSelector
+	pseudos []pseudoSelector
+}
+
+type attrSelector struct {
+	key      string
+	op       string // "" (present), "=", "~=", "|=", "^=", "$=", "*="
+	val      string
+	foldCase bool
+}
+
+type pseudoSelector struct {
+	name string
+	n    int               // for nth-child
+	not  *compoundSelector // for not
+}
+
+// compileSelector parses a selector group.
+func compileSelector(src string) (selector, error) {
+	p := &selectorParser{src: src}
+	sel, err := p.parseGroup()
+	if err != nil {
+		return nil, fmt.Errorf("selector %q: %w", src, err)
+	}
+	return sel, nil
+}
+
+// selectAll returns the elements under root matching sel, in document order.
+func (sel selector) selectAll(root *html.Node) []*html.Node {
+	var out []*html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && sel.matches(n) {
+			out = append(out, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return out
+}
+
+func (sel selector) matches(n *html.Node) bool {
+	for _, c := range sel {
+		if c.matchAt(n, len(c.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAt reports whether n matches compounds[i] and the combinators to its left.
+func (c *complexSelector) matchAt(n *html.Node, i int) bool {
+	if !c.compounds[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch c.combinators[i-1] {
+	case '>':
+		p := parentElement(n)
+		return p != nil && c.matchAt(p, i-1)
+	case '+':
+		s := prevElement(n)
+		return s != nil && c.matchAt(s, i-1)
+	case '~':
+		for s := prevElement(n); s != nil; s = prevElement(s) {
+			if c.matchAt(s, i-1) {
+				return true
+			}
+		}
+	default:
+		for p := parentElement(n); p != nil; p = parentElement(p) {
+			if c.matchAt(p, i-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (cs *compoundSelector) matches(n *html.Node) bool {
+	if cs.tag != "" && cs.tag != n.Data {
+		return false
+	}
+	if cs.id != "" && attrValue(n, "id") != cs.id {
+		return false
+	}
+	if len(cs.classes) > 0 {
+		classes := strings.Fields(attrValue(n, "class"))
+		for _, want := range cs.classes {
+			if !slices.Contains(classes, want) {
+				return false
+			}
+		}
+	}
+	for _, a := range cs.attrs {
+		if !a.matches(n) {
+			return false
+		}
+	}
+	for _, ps := range cs.pseudos {
+		if !ps.matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *attrSelector) matches(n *html.Node) bool {
+	v, ok := attr(n, a.key)
+	if !ok {
+		return false
+	}
+	want := a.val
+	if a.foldCase {
+		v, want = strings.ToLower(v), strings.ToLower(want)
+	}
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return v == want
+	case "~=":
+		return slices.Contains(strings.Fields(v), want)
+	case "|=":
+		return v == want || strings.HasPrefix(v, want+"-")
+	case "^=":
+		return want != "" && strings.HasPrefix(v, want)
+	case "$=":
+		return want != "" && strings.HasSuffix(v, want)
+	case "*=":
+		return want != "" && strings.Contains(v, want)
+	}
+	return false
+}
+
+func (ps *pseudoSelector) matches(n *html.Node) bool {
+	switch ps.name {
+	case "first-child":
+		return prevElement(n) == nil
+	case "last-child":
+		return nextElement(n) == nil
+	case "only-child":
+		return prevElement(n) == nil && nextElement(n) == nil
+	case "nth-child":
+		i := 1
+		for s := prevElement(n); s != nil; s = prevElement(s) {
+			i++
+		}
+		return i == ps.n
+	case "not":
+		return !ps.not.matches(n)
+	}
+	return false
+}
+
+func parentElement(n *html.Node) *html.Node {
+	if p := n.Parent; p != nil && p.Type == html.ElementNode {
+		return p
+	}
+	return nil
+}
+
+func prevElement(n *html.Node) *html.Node {
+	for s := n.PrevSibling; s != nil; s = s.PrevSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for s := n.NextSibling; s != nil; s = s.NextSibling {
+		if s.Type == html.ElementNode {
+			return s
+		}
+	}
+	return nil
+}
+
+type selectorParser struct {
+	src string
+	pos int
+}
+
+func (p *selectorParser) parseGroup() (selector, error) {
+	var sel selector
+	for {
+		c, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, c)
+		p.skipSpace()
+		if p.pos == len(p.src) {
+			return sel, nil
+		}
+		if p.src[p.pos] != ',' {
+			return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos], p.pos)
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	var c complexSelector
+	p.skipSpace()
+	for {
+		cs, err := p.parseCompound()
+		if err != nil {
+			return c, err
+		}
+		c.compounds = append(c.compounds, cs)
+
+		hadSpace := p.skipSpace()
+		if p.pos == len(p.src) || p.src[p.pos] == ',' {
+			return c, nil
+		}
+		switch comb := p.src[p.pos]; comb {
+		case '>', '+', '~':
+			p.pos++
+			p.skipSpace()
+			c.combinators = append(c.combinators, comb)
+		default:
+			if !hadSpace {
+				return c, fmt.Errorf("unexpected %q at offset %d", comb, p.pos)
+			}
+			c.combinators = append(c.combinators, ' ')
+		}
+	}
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var cs compoundSelector
+	start := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '*' {
+		p.pos++
+	} else if name := p.ident(); name != "" {
+		cs.tag = strings.ToLower(name)
+	}
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '#':
+			p.pos++
+			if cs.id = p.ident(); cs.id == "" {
+				return cs, fmt.Errorf("missing id at offset %d", p.pos)
+			}
+		case '.':
+			p.pos++
+			class := p.ident()
+			if class == "" {
+				return cs, fmt.Errorf("missing class name at offset %d", p.pos)
+			}
+			cs.classes = append(cs.classes, class)
+		case '[':
+			a, err := p.parseAttr()
+			if err != nil {
+				return cs, err
+			}
+			cs.attrs = append(cs.attrs, a)
+		case ':':
+			ps, err := p.parsePseudo()
+			if err != nil {
+				return cs, err
+			}
+			cs.pseudos = append(cs.pseudos, ps)
+		default:
+			if p.pos == start {
+				return cs, fmt.Errorf("expected a selector at offset %d", p.pos)
+			}
+			return cs, nil
+		}
+	}
+	if p.pos == start {
+		return cs, fmt.Errorf("expected a selector at offset %d", p.pos)
+	}
+	return cs, nil
+}
+
+func (p *selectorParser) parseAttr() (attrSelector, error) {
+	var a attrSelector
+	p.pos++ // [
+	p.skipSpace()
+	if a.key = strings.ToLower(p.ident()); a.key == "" {
+		return a, fmt.Errorf("missing attribute name at offset %d", p.pos)
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == ']' {
+		p.pos++
+		return a, nil
+	}
+	for _, op := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			a.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if a.op == "" {
+		return a, fmt.Errorf("bad attribute operator at offset %d", p.pos)
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+		quote := p.src[p.pos]
+		end := strings.IndexByte(p.src[p.pos+1:], quote)
+		if end < 0 {
+			return a, fmt.Errorf("unterminated string at offset %d", p.pos)
+		}
+		a.val = p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else if a.val = p.ident(); a.val == "" {
+		return a, fmt.Errorf("missing attribute value at offset %d", p.pos)
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) && (p.src[p.pos] == 'i' || p.src[p.pos] == 'I') {
+		a.foldCase = true
+		p.pos++
+		p.skipSpace()
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+		return a, fmt.Errorf("missing ] at offset %d", p.pos)
+	}
+	p.pos++
+	return a, nil
+}
+
+func (p *selectorParser) parsePseudo() (pseudoSelector, error) {
+	var ps pseudoSelector
+	p.pos++ // :
+	ps.name = strings.ToLower(p.ident())
+	switch ps.name {
+	case "first-child", "last-child", "only-child":
+		return ps, nil
+	case "nth-child", "not":
+	default:
+		return ps, fmt.Errorf("unsupported pseudo-class :%s", ps.name)
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '(' {
+		return ps, fmt.Errorf("missing ( after :%s", ps.name)
+	}
+	p.pos++
+	p.skipSpace()
+	if ps.name == "nth-child" {
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			ps.n = ps.n*10 + int(p.src[p.pos]-'0')
+			p.pos++
+		}
+		if p.pos == start || ps.n == 0 {
+			return ps, fmt.Errorf(":nth-child takes a positive integer")
+		}
+	} else {
+		inner, err := p.parseCompound()
+		if err != nil {
+			return ps, err
+		}
+		ps.not = &inner
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != ')' {
+		return ps, fmt.Errorf("missing ) at offset %d", p.pos)
+	}
+	p.pos++
+	return ps, nil
+}
+
+// ident reads a CSS identifier: letters, digits, '-', '_' and non-ASCII bytes.
+func (p *selectorParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == '-' || c == '_' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+// skipSpace skips whitespace and reports whether there was any.
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.src) && strings.IndexByte(" \t\n\r\f", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+	return p.pos > start
+}
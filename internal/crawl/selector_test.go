@@ -0,0 +1,144 @@
+package crawl
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+const selectorDoc = `<html><head><title>t</title></head><body id="body">
+<div id="main" class="box wide" lang="en-US" data-role="Main Panel">
+  <p id="p1" class="lead">one</p>
+  <p id="p2">two</p>
+  <span id="s1" title="hello world">three</span>
+  <p id="p3" class="lead last">four</p>
+</div>
+<ul id="list">
+  <li id="li1"><a id="a1" href="https://example.com/a.pdf">A</a></li>
+  <li id="li2"><a id="a2" href="/b.html" rel="nofollow">B</a></li>
+</ul>
+<div id="solo"><em id="em1">x</em></div>
+</body></html>`
+
+func parseHTML(t *testing.T, src string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestSelector(t *testing.T) {
+	doc := parseHTML(t, selectorDoc)
+	tests := []struct {
+		sel  string
+		want string // ids of the matches, in document order
+	}{
+		// type, universal, id and class
+		{"p", "p1 p2 p3"},
+		{"LI", "li1 li2"},
+		{"div > *", "p1 p2 s1 p3 em1"},
+		{"#main", "main"},
+		{"p#p2", "p2"},
+		{"span#p2", ""},
+		{".lead", "p1 p3"},
+		{".lead.last", "p3"},
+		{"p.lead", "p1 p3"},
+		{".box.missing", ""},
+
+		// attributes
+		{"[title]", "s1"},
+		{"[ title ]", "s1"},
+		{"[data-role='Main Panel']", "main"},
+		{`[data-role="main panel"]`, ""},
+		{`[data-role="main panel" i]`, "main"},
+		{"[class~=wide]", "main"},
+		{"[class~=wid]", ""},
+		{"[lang|=en]", "main"},
+		{"[lang|=en-US]", "main"},
+		{"[lang|=e]", ""},
+		{"[href^=https]", "a1"},
+		{"[href$='.pdf']", "a1"},
+		{"[href$=PDF i]", "a1"},
+		{"[href*='b.h']", "a2"},
+		{"[href^='']", ""},
+		{"[href$='']", ""},
+		{"[href*='']", ""},
+
+		// pseudo-classes
+		{"li:first-child", "li1"},
+		{"li:last-child", "li2"},
+		{"em:only-child", "em1"},
+		{"a:only-child", "a1 a2"},
+		{"#main > :nth-child(2)", "p2"},
+		{"p:nth-child(4)", "p3"},
+		{"p:not(.lead)", "p2"},
+		{"div > :not(p):not(em)", "s1"},
+		{"LI:FIRST-CHILD A", "a1"},
+
+		// combinators and groups
+		{"div p", "p1 p2 p3"},
+		{"body a", "a1 a2"},
+		{"body > p", ""},
+		{"div  >  p:first-child", "p1"},
+		{"p + span", "s1"},
+		{"span + span", ""},
+		{"p ~ p", "p2 p3"},
+		{"#p1 ~ .lead", "p3"},
+		{"#list li > a[rel]", "a2"},
+		{"a[rel], em", "a2 em1"},
+		{"em , #p1", "p1 em1"},
+	}
+	for _, tt := range tests {
+		sel, err := compileSelector(tt.sel)
+		if err != nil {
+			t.Errorf("compileSelector(%q): %v", tt.sel, err)
+			continue
+		}
+		var ids []string
+		for _, n := range sel.selectAll(doc) {
+			ids = append(ids, attrValue(n, "id"))
+		}
+		if got := strings.Join(ids, " "); got != tt.want {
+			t.Errorf("%q matched %q, want %q", tt.sel, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		" ",
+		"p,",
+		",p",
+		"p)",
+		"p > ",
+		"p > > a",
+		"#",
+		".",
+		"p.",
+		"[",
+		"[=x]",
+		"[a=]",
+		"[a!=x]",
+		`[a="x]`,
+		"[a=x",
+		"[a=x j]",
+		":hover",
+		":first-child(",
+		":nth-child",
+		":nth-child()",
+		":nth-child(0)",
+		":nth-child(x)",
+		":nth-child(2",
+		":not(",
+		":not(p",
+		":not(p q)",
+	} {
+		if _, err := compileSelector(src); err == nil {
+			t.Errorf("compileSelector(%q) succeeded, want an error", src)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"go-crawler/internal/model"
+	"go-crawler/internal/urlnorm"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// crawlSession is the state of one job's crawl: its scope, visited set, queue,
+// scheduler and counters. Every Engine.Start builds its own session, so jobs
+// running at the same time on one Engine share nothing but the Engine's
+// HTTP client, robots.txt cache and writers.
+type crawlSession struct {
+	job      *model.CrawlJob
+	frontier Frontier
+	seedURL  string // normalized start URL
+	norm     *urlnorm.Normalizer
+	scope    *scope
+	rules    *urlRules
+	content  *contentPolicy
+	sources  map[model.LinkSource]bool // link sources the job follows
+	robots   robotsMetaPolicy
+	extract  *extractor // nil if the job has no extraction rules
+
+	visited  *VisitedURLStore
+	urlQueue chan *model.URLTask
+	sched    *hostScheduler
+
+	// activeCount tracks active tasks (queued + in-progress).
+	// Bump up on enqueue, down on finish. When it hits zero, close the queue.
+	// Only one goroutine should close.
+	activeCount atomic.Int32
+}
+
+func newCrawlSession(job *model.CrawlJob, frontier Frontier, hostDelay func(string) time.Duration) (*crawlSession, error) {
+	norm := urlnorm.Default.With(job.Input.StripQueryParams)
+	seed, err := norm.Normalize(job.Input.StartURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start URL: %w", err)
+	}
+	seedUrl, err := url.Parse(seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start URL: %w", err)
+	}
+	scope, err := newScope(job.Input, seedUrl)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := newURLRules(job.Input.URLRules)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := newLinkSources(job.Input)
+	if err != nil {
+		return nil, err
+	}
+	robotsMeta, err := newRobotsMetaPolicy(job.Input)
+	if err != nil {
+		return nil, err
+	}
+	extract, err := newExtractor(job.Input.ExtractionRules)
+	if err != nil {
+		return nil, err
+	}
+	urlQueue := make(chan *model.URLTask, 1000)
+	return &crawlSession{
+		job:      job,
+		frontier: frontier,
+		seedURL:  seed,
+		norm:     norm,
+		scope:    scope,
+		rules:    rules,
+		content:  newContentPolicy(job.Input),
+		sources:  sources,
+		robots:   robotsMeta,
+		extract:  extract,
+		visited:  NewVisitedURLStore(),
+		urlQueue: urlQueue,
+		sched: newHostScheduler(urlQueue,
+			time.Duration(job.Input.RequestDelayMs)*time.Millisecond,
+			job.Input.MaxConcurrentPerHost,
+			hostDelay),
+	}, nil
+}
+
+// start fills the queue from the job's persisted frontier. A new job is seeded with
+// the start URL; a resumed job has every URL it already discovered marked visited and
+// its unfinished URLs queued again. The scheduler must already be running, since the
+// backlog may exceed the queue's buffer. If there is nothing to crawl, or loading
+// fails, the queue is closed so the scheduler and workers exit.
+func (s *crawlSession) start(ctx context.Context) error {
+	entries, err := s.frontier.LoadFrontier(ctx, s.job.ID)
+	if err != nil {
+		close(s.urlQueue)
+		return fmt.Errorf("load frontier: %w", err)
+	}
+	if len(entries) == 0 {
+		s.activeCount.Store(1)
+		s.visited.MarkIfNotVisited(s.seedURL)
+		task := &model.URLTask{
+			URL:   s.seedURL,
+			Depth: 0,
+		}
+		s.persist(ctx, task)
+		s.urlQueue <- task
+		return nil
+	}
+
+	var pending []*model.URLTask
+	for _, entry := range entries {
+		s.visited.MarkIfNotVisited(entry.URL)
+		if entry.State == model.FrontierQueued {
+			pending = append(pending, &model.URLTask{
+				URL:            entry.URL,
+				Depth:          entry.Depth,
+				DiscoveredFrom: entry.DiscoveredFrom,
+				Attempt:        entry.Attempts,
+				NotBefore:      entry.NextAttemptAt,
+			})
+		}
+	}
+	fmt.Println("[crawl] Resuming job", s.job.ID, "with", len(pending), "pending of", len(entries), "URLs")
+	if len(pending) == 0 {
+		close(s.urlQueue)
+		return nil
+	}
+	// Count the whole backlog first so a fast worker cannot bring activeCount to 0 early.
+	s.activeCount.Store(int32(len(pending)))
+	for _, task := range pending {
+		s.urlQueue <- task
+	}
+	return nil
+}
+
+// enqueue marks task's URL visited, persists it to the frontier and queues it. It
+// returns false if the URL was already visited or ctx was cancelled before the task
+// could be queued.
+func (s *crawlSession) enqueue(ctx context.Context, task *model.URLTask) bool {
+	if !s.visited.MarkIfNotVisited(task.URL) {
+		return false
+	}
+	s.persist(ctx, task)
+	s.activeCount.Add(1)
+	select {
+	case s.urlQueue <- task:
+		return true
+	case <-ctx.Done():
+		// Never reaches zero here: the caller's task still holds its own count.
+		s.activeCount.Add(-1)
+		return false
+	}
+}
+
+// persist adds task to the frontier. On failure the crawl goes on in memory;
+// only resuming after a restart would miss the URL.
+func (s *crawlSession) persist(ctx context.Context, task *model.URLTask) {
+	if err := s.frontier.AddFrontierTask(ctx, s.job.ID, task); err != nil {
+		fmt.Println("[crawl] Error persisting frontier URL:", err)
+	}
+}
+
+// retry queues task again for a later attempt, delayed by exponential backoff or the
+// server's Retry-After, whichever is longer. It returns the queued task, or false if
+// the job's MaxAttempts is used up or retryAfter exceeds maxRetryAfter.
+func (s *crawlSession) retry(ctx context.Context, task *model.URLTask, retryAfter time.Duration) (*model.URLTask, bool) {
+	maxAttempts := s.job.Input.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	attempt := task.Attempt + 1
+	if attempt >= maxAttempts || retryAfter > maxRetryAfter {
+		return nil, false
+	}
+	delay := max(backoff(attempt), retryAfter)
+	next := &model.URLTask{
+		URL:            task.URL,
+		Depth:          task.Depth,
+		DiscoveredFrom: task.DiscoveredFrom,
+		Attempt:        attempt,
+		NotBefore:      time.Now().Add(delay),
+	}
+	if err := s.frontier.RetryFrontierTask(ctx, s.job.ID, next); err != nil {
+		fmt.Println("[crawl] Error persisting frontier retry:", err)
+	}
+	s.activeCount.Add(1)
+	select {
+	case s.urlQueue <- next:
+	case <-ctx.Done():
+		// The frontier still holds the task as QUEUED, so a resumed crawl retries it.
+		s.activeCount.Add(-1)
+	}
+	return next, true
+}
+
+// setState records a URL's frontier state. Failures are logged and otherwise ignored.
+func (s *crawlSession) setState(ctx context.Context, rawURL string, state model.FrontierState) {
+	if err := s.frontier.SetFrontierState(ctx, s.job.ID, rawURL, state); err != nil {
+		fmt.Println("[crawl] Error updating frontier state:", err)
+	}
+}
+
+// done finishes a task taken from the scheduler and records state in the frontier.
+// Nothing is recorded for a task left QUEUED (it was requeued for a retry, or ctx was
+// cancelled before it was processed). The caller that brings activeCount to 0 closes
+// the queue, which shuts down the scheduler and workers.
+func (s *crawlSession) done(ctx context.Context, task *model.URLTask, state model.FrontierState) {
+	if ctx.Err() == nil && state != model.FrontierQueued {
+		s.setState(ctx, task.URL, state)
+	}
+	// Free the host slot before decrementing: once activeCount hits zero the
+	// scheduler stops receiving.
+	s.sched.release(taskHost(task))
+	if s.activeCount.Add(-1) == 0 {
+		close(s.urlQueue)
+	}
+}
+
+// filterLinks keeps the links found on a page that come from the job's link sources,
+// normalizes them and applies nofollow and the job's scope and URL rules. Every link is
+// nofollow when the page is. It returns the links that may be enqueued, with their URLs
+// normalized, and stats recording every rejection.
+func (s *crawlSession) filterLinks(links []model.Link, pageNoFollow bool) ([]model.Link, model.PageStats) {
+	var stats model.PageStats
+	var accepted []model.Link
+	for _, l := range links {
+		if !s.sources[l.Source] {
+			continue
+		}
+		stats.LinksFound++
+		link, err := s.norm.Normalize(l.URL)
+		if err != nil {
+			continue
+		}
+		if pageNoFollow || (s.robots.noFollow && slices.Contains(l.Rel, "nofollow")) {
+			stats.RejectedLinks = append(stats.RejectedLinks, model.RejectedLink{URL: link, Reason: model.SkipReasonNoFollow})
+			continue
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		if reason, rule, ok := s.checkLink(u); !ok {
+			stats.RejectedLinks = append(stats.RejectedLinks, model.RejectedLink{URL: link, Reason: reason, Rule: rule})
+			continue
+		}
+		l.URL = link
+		accepted = append(accepted, l)
+	}
+	stats.LinksAccepted = len(accepted)
+	return accepted, stats
+}
+
+// canonicalURL normalizes a page's declared canonical URL. It returns "" if there is
+// none or it is not a valid URL, and whether the job may enqueue it (in scope and
+// allowed by the URL rules).
+func (s *crawlSession) canonicalURL(raw string) (canonical string, followable bool) {
+	if raw == "" {
+		return "", false
+	}
+	canonical, err := s.norm.Normalize(raw)
+	if err != nil {
+		return "", false
+	}
+	u, err := url.Parse(canonical)
+	if err != nil {
+		return canonical, false
+	}
+	_, _, ok := s.checkLink(u)
+	return canonical, ok
+}
+
+// checkLink applies the job's scope and URL rules to a normalized URL. When u is
+// rejected, reason says why and rule names the rejecting URL rule, if any.
+func (s *crawlSession) checkLink(u *url.URL) (reason model.SkipReason, rule string, ok bool) {
+	if !s.scope.allows(u) {
+		return model.SkipReasonOutOfScope, "", false
+	}
+	if ok, rule := s.rules.check(u); !ok {
+		if rule == "" {
+			return model.SkipReasonNoInclude, "", false
+		}
+		return model.SkipReasonURLRule, rule, false
+	}
+	return "", "", true
+}
+
+// claimRedirect marks finalURL, the normalized URL task was redirected to, visited and
+// adds it to the frontier. It returns false if the URL was already visited, in which
+// case the content is crawled (or was) under its own task.
+func (s *crawlSession) claimRedirect(ctx context.Context, task *model.URLTask, finalURL string) bool {
+	if !s.visited.MarkIfNotVisited(finalURL) {
+		return false
+	}
+	s.persist(ctx, &model.URLTask{
+		URL:            finalURL,
+		Depth:          task.Depth,
+		DiscoveredFrom: task.URL,
+	})
+	return true
+}
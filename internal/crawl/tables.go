@@ -0,0 +1,206 @@
+package crawl
+
+import (
+	"go-crawler/internal/model"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// colspan and rowspan are capped as browsers do, so a bogus value cannot blow up the
+// grid.
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// A page cannot make the crawler build huge grids: a table is cut off before the row
+// that would take it past maxTableCells slots, and only a page's first
+// maxTablesPerPage tables are kept.
+const (
+	maxTableCells    = 100_000
+	maxTablesPerPage = 100
+)
+
+// extractTables returns every table in doc with at least one cell, in document order,
+// up to maxTablesPerPage. Nested tables are returned as tables of their own and left
+// out of their outer cell.
+func extractTables(doc *html.Node) []model.Table {
+	var tables []model.Table
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if len(tables) == maxTablesPerPage {
+			return
+		}
+		if n.Type == html.ElementNode {
+			if skippedElements[n.Data] || isHidden(n) {
+				return
+			}
+			if n.Data == "table" {
+				if t, ok := extractTable(n); ok {
+					tables = append(tables, t)
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return tables
+}
+
+// tableRow is a <tr> of a table and whether it sits in the table's <thead>.
+type tableRow struct {
+	tr     *html.Node
+	inHead bool
+}
+
+// extractTable lays a table's cells out on a grid, copying a spanning cell's text into
+// every slot it covers. Rows in <thead>, or else the leading rows made only of <th>
+// cells, become the header. Rows past maxTableCells are dropped.
+func extractTable(table *html.Node) (model.Table, bool) {
+	var t model.Table
+	var rows []tableRow
+	for c := table.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "caption":
+			t.Caption = cellText(c)
+		case "tr":
+			rows = append(rows, tableRow{tr: c})
+		case "thead", "tbody", "tfoot":
+			for r := c.FirstChild; r != nil; r = r.NextSibling {
+				if r.Type == html.ElementNode && r.Data == "tr" {
+					rows = append(rows, tableRow{tr: r, inHead: c.Data == "thead"})
+				}
+			}
+		}
+	}
+
+	var grid [][]string
+	allTH := make([]bool, len(rows))
+	width := 0
+rows:
+	for i, row := range rows {
+		if width*(i+1) > maxTableCells {
+			grid, rows = grid[:i], rows[:i]
+			break
+		}
+		for len(grid) <= i {
+			grid = append(grid, nil)
+		}
+		allTH[i] = true
+		col := 0
+		for cell := row.tr.FirstChild; cell != nil; cell = cell.NextSibling {
+			if cell.Type != html.ElementNode || (cell.Data != "td" && cell.Data != "th") {
+				continue
+			}
+			if cell.Data == "td" {
+				allTH[i] = false
+			}
+			for col < len(grid[i]) && grid[i][col] != nilCell {
+				col++
+			}
+			colspan := span(cell, "colspan", maxColspan)
+			rowspan := span(cell, "rowspan", maxRowspan)
+			if rowspan == 0 { // rowspan="0" spans the rest of the table
+				rowspan = len(rows) - i
+			}
+			rowspan = min(rowspan, len(rows)-i)
+			// Every row ends up padded to the widest; cut the table off before this row
+			// if the cell would take the grid past maxTableCells.
+			wide := max(width, len(grid[i]), col+colspan)
+			tall := max(len(grid), i+rowspan)
+			if wide*tall > maxTableCells {
+				grid, rows = grid[:i], rows[:i]
+				break rows
+			}
+			text := cellText(cell)
+			for r := i; r < i+rowspan; r++ {
+				for len(grid) <= r {
+					grid = append(grid, nil)
+				}
+				for c := col; c < col+colspan; c++ {
+					for len(grid[r]) <= c {
+						grid[r] = append(grid[r], nilCell)
+					}
+					grid[r][c] = text
+				}
+			}
+			col += colspan
+		}
+		width = max(width, len(grid[i]))
+	}
+	if width == 0 {
+		return t, false
+	}
+	for i := range grid {
+		for len(grid[i]) < width {
+			grid[i] = append(grid[i], nilCell)
+		}
+		for c := range grid[i] {
+			if grid[i][c] == nilCell {
+				grid[i][c] = ""
+			}
+		}
+	}
+
+	headerRows := 0
+	for headerRows < len(rows) && rows[headerRows].inHead {
+		headerRows++
+	}
+	if headerRows == 0 {
+		for headerRows < len(rows) && allTH[headerRows] {
+			headerRows++
+		}
+		if headerRows == len(rows) {
+			headerRows = 0 // all <th>: no way to tell header from data
+		}
+	}
+	if headerRows > 0 {
+		t.Header = make([]string, width)
+		for c := 0; c < width; c++ {
+			var parts []string
+			for r := 0; r < headerRows; r++ {
+				if v := grid[r][c]; v != "" && (len(parts) == 0 || parts[len(parts)-1] != v) {
+					parts = append(parts, v)
+				}
+			}
+			t.Header[c] = strings.Join(parts, " / ")
+		}
+	}
+	t.Rows = grid[headerRows:]
+	return t, true
+}
+
+// nilCell marks grid slots no cell has covered yet; it cannot occur in cell text,
+// which has its whitespace collapsed.
+const nilCell = "\x00"
+
+// span reads a colspan or rowspan attribute; missing or invalid values are 1.
+func span(cell *html.Node, key string, limit int) int {
+	v, ok := attr(cell, key)
+	if !ok {
+		return 1
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n < 0 || (n == 0 && key == "colspan") {
+		return 1
+	}
+	return min(n, limit)
+}
+
+// cellText is the visible text of a cell or caption on one line, without any nested
+// tables.
+func cellText(n *html.Node) string {
+	x := &textExtractor{skip: func(n *html.Node) bool { return n.Data == "table" }}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		x.walk(c, BlockParagraph, 0)
+	}
+	x.flush()
+	return strings.Join(strings.Fields(joinBlocks(x.blocks)), " ")
+}
@@ -0,0 +1,126 @@
+package crawl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func TestExtractTables(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want []model.Table
+	}{
+		{
+			"plain",
+			`<table><tr><td>a</td><td>b</td></tr><tr><td>c</td><td> d  e </td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "b"}, {"c", "d e"}}}},
+		},
+		{
+			"colspan",
+			`<table><tr><td colspan="2">a</td><td>b</td></tr><tr><td>c</td><td>d</td><td>e</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "a", "b"}, {"c", "d", "e"}}}},
+		},
+		{
+			"rowspan",
+			`<table><tr><td rowspan="2">a</td><td>b</td></tr><tr><td>c</td></tr><tr><td>d</td><td>e</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "b"}, {"a", "c"}, {"d", "e"}}}},
+		},
+		{
+			"row and column span",
+			`<table><tr><td rowspan="2" colspan="2">a</td><td>b</td></tr><tr><td>c</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "a", "b"}, {"a", "a", "c"}}}},
+		},
+		{
+			"rowspan zero spans the rest",
+			`<table><tr><td rowspan="0">a</td><td>b</td></tr><tr><td>c</td></tr><tr><td>d</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "b"}, {"a", "c"}, {"a", "d"}}}},
+		},
+		{
+			"rowspan past the last row",
+			`<table><tr><td rowspan="5">a</td><td>b</td></tr><tr><td>c</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "b"}, {"a", "c"}}}},
+		},
+		{
+			"invalid spans are 1",
+			`<table><tr><td colspan="0">a</td><td colspan="x">b</td><td rowspan="-1">c</td></tr><tr><td>d</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "b", "c"}, {"d", "", ""}}}},
+		},
+		{
+			"ragged rows padded",
+			`<table><tr><td>a</td></tr><tr><td>b</td><td>c</td><td>d</td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a", "", ""}, {"b", "c", "d"}}}},
+		},
+		{
+			"thead header",
+			`<table><caption> Prices </caption><thead><tr><td>name</td><td>price</td></tr></thead><tbody><tr><td>a</td><td>1</td></tr></tbody></table>`,
+			[]model.Table{{Caption: "Prices", Header: []string{"name", "price"}, Rows: [][]string{{"a", "1"}}}},
+		},
+		{
+			"th rows header, joined",
+			`<table><tr><th colspan="2">size</th><th rowspan="2">name</th></tr><tr><th>w</th><th>h</th></tr><tr><td>1</td><td>2</td><td>x</td></tr></table>`,
+			[]model.Table{{Header: []string{"size / w", "size / h", "name"}, Rows: [][]string{{"1", "2", "x"}}}},
+		},
+		{
+			"all th is data",
+			`<table><tr><th>a</th></tr><tr><th>b</th></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"a"}, {"b"}}}},
+		},
+		{
+			"nested table listed separately",
+			`<table><tr><td>outer<table><tr><td>inner</td></tr></table></td></tr></table>`,
+			[]model.Table{{Rows: [][]string{{"outer"}}}, {Rows: [][]string{{"inner"}}}},
+		},
+		{
+			"empty and hidden tables dropped",
+			`<table></table><table hidden><tr><td>a</td></tr></table><table><tr></tr></table>`,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		got := extractTables(parseHTML(t, tt.html))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %#v\nwant %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractTablesLimits(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < maxTablesPerPage+5; i++ {
+		fmt.Fprintf(&b, "<table><tr><td>%d</td></tr></table>", i)
+	}
+	tables := extractTables(parseHTML(t, b.String()))
+	if len(tables) != maxTablesPerPage || tables[maxTablesPerPage-1].Rows[0][0] != fmt.Sprint(maxTablesPerPage-1) {
+		t.Errorf("kept %d tables, want the first %d", len(tables), maxTablesPerPage)
+	}
+
+	tests := []struct {
+		name     string
+		html     string
+		wantRows int
+	}{
+		// 1000 columns by 65534 rows from a single cell: cut off before its row.
+		{"huge span", `<table><tr><td>a</td></tr><tr><td colspan="1000" rowspan="65534">b</td></tr>` + strings.Repeat("<tr></tr>", 200) + `</table>`, 1},
+		// A wide first row, then empty rows that are each padded to its width.
+		{"wide row then many rows", `<table><tr><td colspan="1000">a</td></tr>` + strings.Repeat("<tr><td>b</td></tr>", 200) + `</table>`, maxTableCells / 1000},
+	}
+	for _, tt := range tests {
+		tables := extractTables(parseHTML(t, tt.html))
+		if len(tables) != 1 {
+			t.Errorf("%s: %d tables, want 1", tt.name, len(tables))
+			continue
+		}
+		cells := 0
+		for _, row := range tables[0].Rows {
+			cells += len(row)
+		}
+		if len(tables[0].Rows) != tt.wantRows || cells > maxTableCells {
+			t.Errorf("%s: %d rows, %d cells; want %d rows, at most %d cells", tt.name, len(tables[0].Rows), cells, tt.wantRows, maxTableCells)
+		}
+	}
+}
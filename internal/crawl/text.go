@@ -0,0 +1,153 @@
+package crawl
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// BlockKind is the role of a block of text in a page.
+type BlockKind string
+
+const (
+	BlockHeading   BlockKind = "HEADING"
+	BlockParagraph BlockKind = "PARAGRAPH"
+	BlockListItem  BlockKind = "LIST_ITEM"
+	BlockPre       BlockKind = "PRE" // whitespace preserved
+)
+
+// TextBlock is one heading, paragraph, list item or preformatted block of visible text.
+// Level is 1-6 for headings and 0 otherwise.
+type TextBlock struct {
+	Kind  BlockKind
+	Level int
+	Text  string
+}
+
+// skippedElements never contain visible text.
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "math": true, "canvas": true, "iframe": true, "object": true,
+	"embed": true, "audio": true, "video": true, "select": true, "textarea": true,
+	"button": true,
+}
+
+// blockElements end the current block and start a new one.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true,
+	"caption": true, "dd": true, "details": true, "dialog": true, "div": true, "dl": true,
+	"dt": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hgroup": true, "hr": true, "li": true, "main": true, "nav": true,
+	"ol": true, "p": true, "pre": true, "section": true, "summary": true, "table": true,
+	"tbody": true, "tfoot": true, "thead": true, "tr": true, "ul": true,
+}
+
+// separatorElements are inline but must not glue their neighbours' words together.
+var separatorElements = map[string]bool{"br": true, "td": true, "th": true, "img": true, "input": true}
+
+// extractText returns the visible text of doc as blocks. Non-visible elements (script,
+// style, noscript, template, hidden elements, ...) are skipped, block elements start a
+// new block, and whitespace is collapsed except inside <pre>.
+func extractText(doc *html.Node) []TextBlock {
+	x := &textExtractor{}
+	x.walk(doc, BlockParagraph, 0)
+	x.flush()
+	return x.blocks
+}
+
+// joinBlocks renders blocks as plain text, one blank line between blocks.
+func joinBlocks(blocks []TextBlock) string {
+	texts := make([]string, len(blocks))
+	for i, b := range blocks {
+		texts[i] = b.Text
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+type textExtractor struct {
+	blocks  []TextBlock
+	buf     strings.Builder
+	hasText bool      // buf holds more than whitespace
+	kind    BlockKind // kind and level of the text in buf
+	level   int
+	skip    func(*html.Node) bool // optional: further elements to leave out
+}
+
+func (x *textExtractor) walk(n *html.Node, kind BlockKind, level int) {
+	switch n.Type {
+	case html.TextNode:
+		if !x.hasText && strings.TrimSpace(n.Data) != "" {
+			x.kind, x.level, x.hasText = kind, level, true
+		}
+		x.buf.WriteString(n.Data)
+		return
+	case html.ElementNode:
+		if skippedElements[n.Data] || isHidden(n) || (x.skip != nil && x.skip(n)) {
+			return
+		}
+		if separatorElements[n.Data] {
+			x.buf.WriteString(" ")
+		}
+		if !blockElements[n.Data] {
+			break
+		}
+		x.flush()
+		switch n.Data {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			kind, level = BlockHeading, int(n.Data[1]-'0')
+		case "li":
+			kind, level = BlockListItem, 0
+		case "pre":
+			kind, level = BlockPre, 0
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			x.walk(c, kind, level)
+		}
+		x.flush()
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		x.walk(c, kind, level)
+	}
+}
+
+// flush ends the current block, if it has any visible text.
+func (x *textExtractor) flush() {
+	raw := x.buf.String()
+	x.buf.Reset()
+	x.hasText = false
+	var text string
+	if x.kind == BlockPre {
+		text = strings.Trim(raw, "\r\n")
+		if strings.TrimSpace(text) == "" {
+			text = ""
+		}
+	} else {
+		text = strings.Join(strings.Fields(raw), " ")
+	}
+	if text != "" {
+		x.blocks = append(x.blocks, TextBlock{Kind: x.kind, Level: x.level, Text: text})
+	}
+}
+
+// isHidden reports whether n is hidden with the hidden attribute, aria-hidden or an
+// inline display:none / visibility:hidden style.
+func isHidden(n *html.Node) bool {
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if strings.EqualFold(strings.TrimSpace(attr.Val), "true") {
+				return true
+			}
+		case "style":
+			style := strings.ToLower(strings.ReplaceAll(attr.Val, " ", ""))
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
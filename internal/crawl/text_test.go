@@ -0,0 +1,47 @@
+package crawl
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// TestExtractTextGolden parses testdata/text.html and compares its blocks and text
+// content with testdata/text.golden. Run with -update after an intended change.
+func TestExtractTextGolden(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("testdata", "text.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := ParsePage("https://example.com/", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, block := range page.Blocks {
+		fmt.Fprintf(&b, "%s %d: %q\n", block.Kind, block.Level, block.Text)
+	}
+	b.WriteString("---\n")
+	b.WriteString(page.TextContent)
+	b.WriteString("\n")
+	got := b.String()
+
+	golden := filepath.Join("testdata", "text.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("blocks and text content differ from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
@@ -11,9 +11,68 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelPendingJob = `-- name: CancelPendingJob :execrows
+UPDATE jobs SET status = 'CANCELLED', updated_at = NOW() WHERE id = $1 AND status = 'PENDING'
+`
+
+func (q *Queries) CancelPendingJob(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelPendingJob, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const claimNextPendingJob = `-- name: ClaimNextPendingJob :one
+UPDATE jobs SET status = 'RUNNING', owner_instance = $1, heartbeat_at = NOW(), updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs WHERE status = 'PENDING'
+    ORDER BY priority DESC, created_at, id
+    LIMIT 1 FOR UPDATE SKIP LOCKED
+) RETURNING id, input, status, error, pages_crawled, created_at, updated_at, owner_instance, heartbeat_at, priority
+`
+
+func (q *Queries) ClaimNextPendingJob(ctx context.Context, ownerInstance pgtype.Text) (Job, error) {
+	row := q.db.QueryRow(ctx, claimNextPendingJob, ownerInstance)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Input,
+		&i.Status,
+		&i.Error,
+		&i.PagesCrawled,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerInstance,
+		&i.HeartbeatAt,
+		&i.Priority,
+	)
+	return i, err
+}
+
+const countPendingJobsAhead = `-- name: CountPendingJobsAhead :one
+SELECT COUNT(*) FROM jobs
+WHERE status = 'PENDING'
+  AND (priority > $1
+    OR (priority = $1 AND (created_at, id) < ($2::timestamptz, $3::uuid)))
+`
+
+type CountPendingJobsAheadParams struct {
+	Priority  int32              `json:"priority"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ID        pgtype.UUID        `json:"id"`
+}
+
+func (q *Queries) CountPendingJobsAhead(ctx context.Context, arg CountPendingJobsAheadParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countPendingJobsAhead, arg.Priority, arg.CreatedAt, arg.ID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createJob = `-- name: CreateJob :one
-INSERT INTO jobs (id, input, status, error, pages_crawled, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, input, status, error, pages_crawled, created_at, updated_at
+INSERT INTO jobs (id, input, status, error, pages_crawled, priority, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, input, status, error, pages_crawled, created_at, updated_at, owner_instance, heartbeat_at, priority
 `
 
 type CreateJobParams struct {
@@ -22,6 +81,7 @@ type CreateJobParams struct {
 	Status       string             `json:"status"`
 	Error        pgtype.Text        `json:"error"`
 	PagesCrawled int32              `json:"pages_crawled"`
+	Priority     int32              `json:"priority"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
@@ -33,6 +93,7 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		arg.Status,
 		arg.Error,
 		arg.PagesCrawled,
+		arg.Priority,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -45,12 +106,15 @@ func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, erro
 		&i.PagesCrawled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerInstance,
+		&i.HeartbeatAt,
+		&i.Priority,
 	)
 	return i, err
 }
 
 const getAllJobs = `-- name: GetAllJobs :many
-SELECT id, input, status, error, pages_crawled, created_at, updated_at FROM jobs
+SELECT id, input, status, error, pages_crawled, created_at, updated_at, owner_instance, heartbeat_at, priority FROM jobs
 `
 
 func (q *Queries) GetAllJobs(ctx context.Context) ([]Job, error) {
@@ -70,6 +134,9 @@ func (q *Queries) GetAllJobs(ctx context.Context) ([]Job, error) {
 			&i.PagesCrawled,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.OwnerInstance,
+			&i.HeartbeatAt,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -82,7 +149,7 @@ func (q *Queries) GetAllJobs(ctx context.Context) ([]Job, error) {
 }
 
 const getJob = `-- name: GetJob :one
-SELECT id, input, status, error, pages_crawled, created_at, updated_at FROM jobs WHERE id = $1
+SELECT id, input, status, error, pages_crawled, created_at, updated_at, owner_instance, heartbeat_at, priority FROM jobs WHERE id = $1
 `
 
 func (q *Queries) GetJob(ctx context.Context, id pgtype.UUID) (Job, error) {
@@ -96,13 +163,45 @@ func (q *Queries) GetJob(ctx context.Context, id pgtype.UUID) (Job, error) {
 		&i.PagesCrawled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerInstance,
+		&i.HeartbeatAt,
+		&i.Priority,
 	)
 	return i, err
 }
 
+const releaseOrphanedJob = `-- name: ReleaseOrphanedJob :execrows
+UPDATE jobs SET status = $1, error = $2, updated_at = NOW()
+WHERE id = $3 AND status = 'RUNNING'
+  AND (owner_instance IS NULL OR owner_instance = $4
+    OR heartbeat_at IS NULL OR heartbeat_at < NOW() - $5::interval)
+`
+
+type ReleaseOrphanedJobParams struct {
+	Status      string          `json:"status"`
+	Error       pgtype.Text     `json:"error"`
+	ID          pgtype.UUID     `json:"id"`
+	OwnInstance pgtype.Text     `json:"own_instance"`
+	StaleAfter  pgtype.Interval `json:"stale_after"`
+}
+
+func (q *Queries) ReleaseOrphanedJob(ctx context.Context, arg ReleaseOrphanedJobParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseOrphanedJob,
+		arg.Status,
+		arg.Error,
+		arg.ID,
+		arg.OwnInstance,
+		arg.StaleAfter,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const tryIncrementPagesCrawled = `-- name: TryIncrementPagesCrawled :one
 UPDATE jobs SET pages_crawled = pages_crawled + 1, updated_at = NOW()
-WHERE id = $1 AND pages_crawled < $2 RETURNING id, input, status, error, pages_crawled, created_at, updated_at
+WHERE id = $1 AND pages_crawled < $2 RETURNING id, input, status, error, pages_crawled, created_at, updated_at, owner_instance, heartbeat_at, priority
 `
 
 type TryIncrementPagesCrawledParams struct {
@@ -121,12 +220,29 @@ func (q *Queries) TryIncrementPagesCrawled(ctx context.Context, arg TryIncrement
 		&i.PagesCrawled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerInstance,
+		&i.HeartbeatAt,
+		&i.Priority,
 	)
 	return i, err
 }
 
+const updateJobHeartbeat = `-- name: UpdateJobHeartbeat :exec
+UPDATE jobs SET owner_instance = $1, heartbeat_at = NOW() WHERE id = $2
+`
+
+type UpdateJobHeartbeatParams struct {
+	OwnerInstance pgtype.Text `json:"owner_instance"`
+	ID            pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) UpdateJobHeartbeat(ctx context.Context, arg UpdateJobHeartbeatParams) error {
+	_, err := q.db.Exec(ctx, updateJobHeartbeat, arg.OwnerInstance, arg.ID)
+	return err
+}
+
 const updateJobStatus = `-- name: UpdateJobStatus :one
-UPDATE jobs SET status = $1, error = $2, updated_at = NOW() WHERE id = $3 RETURNING id, input, status, error, pages_crawled, created_at, updated_at
+UPDATE jobs SET status = $1, error = $2, updated_at = NOW() WHERE id = $3 RETURNING id, input, status, error, pages_crawled, created_at, updated_at, owner_instance, heartbeat_at, priority
 `
 
 type UpdateJobStatusParams struct {
@@ -146,6 +262,9 @@ func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams
 		&i.PagesCrawled,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.OwnerInstance,
+		&i.HeartbeatAt,
+		&i.Priority,
 	)
 	return i, err
 }
@@ -8,22 +8,88 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Fetch struct {
+	ID          int64              `json:"id"`
+	JobID       pgtype.UUID        `json:"job_id"`
+	Url         string             `json:"url"`
+	Attempt     int32              `json:"attempt"`
+	StatusCode  int32              `json:"status_code"`
+	LatencyMs   int32              `json:"latency_ms"`
+	Bytes       int64              `json:"bytes"`
+	ContentType string             `json:"content_type"`
+	ErrorClass  string             `json:"error_class"`
+	Error       string             `json:"error"`
+	FetchedAt   pgtype.Timestamptz `json:"fetched_at"`
+	Method      string             `json:"method"`
+}
+
+type Frontier struct {
+	ID             int64              `json:"id"`
+	JobID          pgtype.UUID        `json:"job_id"`
+	Url            string             `json:"url"`
+	Depth          int32              `json:"depth"`
+	State          string             `json:"state"`
+	DiscoveredFrom pgtype.Text        `json:"discovered_from"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	Attempts       int32              `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamptz `json:"next_attempt_at"`
+}
+
 type Job struct {
-	ID           pgtype.UUID        `json:"id"`
-	Input        []byte             `json:"input"`
-	Status       string             `json:"status"`
-	Error        pgtype.Text        `json:"error"`
-	PagesCrawled int32              `json:"pages_crawled"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	ID            pgtype.UUID        `json:"id"`
+	Input         []byte             `json:"input"`
+	Status        string             `json:"status"`
+	Error         pgtype.Text        `json:"error"`
+	PagesCrawled  int32              `json:"pages_crawled"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+	OwnerInstance pgtype.Text        `json:"owner_instance"`
+	HeartbeatAt   pgtype.Timestamptz `json:"heartbeat_at"`
+	Priority      int32              `json:"priority"`
+}
+
+type Link struct {
+	ID           int64              `json:"id"`
+	JobID        pgtype.UUID        `json:"job_id"`
+	SourceUrl    string             `json:"source_url"`
+	TargetUrl    string             `json:"target_url"`
+	AnchorText   string             `json:"anchor_text"`
+	Rel          string             `json:"rel"`
+	Element      string             `json:"element"`
+	Depth        int32              `json:"depth"`
+	DiscoveredAt pgtype.Timestamptz `json:"discovered_at"`
 }
 
 type Page struct {
-	ID          int32              `json:"id"`
-	JobID       pgtype.UUID        `json:"job_id"`
-	Url         string             `json:"url"`
-	Title       pgtype.Text        `json:"title"`
-	Html        string             `json:"html"`
-	TextContent string             `json:"text_content"`
-	FetchedAt   pgtype.Timestamptz `json:"fetched_at"`
+	ID              int32              `json:"id"`
+	JobID           pgtype.UUID        `json:"job_id"`
+	Url             string             `json:"url"`
+	Title           pgtype.Text        `json:"title"`
+	Html            string             `json:"html"`
+	TextContent     string             `json:"text_content"`
+	FetchedAt       pgtype.Timestamptz `json:"fetched_at"`
+	Stats           []byte             `json:"stats"`
+	StatusCode      pgtype.Int4        `json:"status_code"`
+	FinalUrl        pgtype.Text        `json:"final_url"`
+	ContentType     pgtype.Text        `json:"content_type"`
+	Charset         pgtype.Text        `json:"charset"`
+	Headers         []byte             `json:"headers"`
+	ContentLength   pgtype.Int8        `json:"content_length"`
+	FetchDurationMs pgtype.Int4        `json:"fetch_duration_ms"`
+	ContentHash     pgtype.Text        `json:"content_hash"`
+	RedirectChain   []byte             `json:"redirect_chain"`
+	MainContent     string             `json:"main_content"`
+	CanonicalUrl    pgtype.Text        `json:"canonical_url"`
+	Metadata        []byte             `json:"metadata"`
+	Extracted       []byte             `json:"extracted"`
+	Tables          []byte             `json:"tables"`
+}
+
+type SkippedUrl struct {
+	ID        int32              `json:"id"`
+	JobID     pgtype.UUID        `json:"job_id"`
+	Url       string             `json:"url"`
+	Reason    string             `json:"reason"`
+	SkippedAt pgtype.Timestamptz `json:"skipped_at"`
 }
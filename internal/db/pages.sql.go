@@ -12,7 +12,7 @@ import (
 )
 
 const getPagesByJobID = `-- name: GetPagesByJobID :many
-SELECT id, job_id, url, title, html, text_content, fetched_at FROM pages WHERE job_id = $1
+SELECT id, job_id, url, title, html, text_content, fetched_at, stats, status_code, final_url, content_type, charset, headers, content_length, fetch_duration_ms, content_hash, redirect_chain, main_content, canonical_url, metadata, extracted, tables FROM pages WHERE job_id = $1
 `
 
 func (q *Queries) GetPagesByJobID(ctx context.Context, jobID pgtype.UUID) ([]Page, error) {
@@ -32,6 +32,21 @@ func (q *Queries) GetPagesByJobID(ctx context.Context, jobID pgtype.UUID) ([]Pag
 			&i.Html,
 			&i.TextContent,
 			&i.FetchedAt,
+			&i.Stats,
+			&i.StatusCode,
+			&i.FinalUrl,
+			&i.ContentType,
+			&i.Charset,
+			&i.Headers,
+			&i.ContentLength,
+			&i.FetchDurationMs,
+			&i.ContentHash,
+			&i.RedirectChain,
+			&i.MainContent,
+			&i.CanonicalUrl,
+			&i.Metadata,
+			&i.Extracted,
+			&i.Tables,
 		); err != nil {
 			return nil, err
 		}
@@ -43,14 +58,47 @@ func (q *Queries) GetPagesByJobID(ctx context.Context, jobID pgtype.UUID) ([]Pag
 	return items, nil
 }
 
+const listExtractedByJobID = `-- name: ListExtractedByJobID :many
+SELECT id, url, extracted FROM pages WHERE job_id = $1 AND extracted IS NOT NULL ORDER BY id
+`
+
+type ListExtractedByJobIDRow struct {
+	ID        int32  `json:"id"`
+	Url       string `json:"url"`
+	Extracted []byte `json:"extracted"`
+}
+
+func (q *Queries) ListExtractedByJobID(ctx context.Context, jobID pgtype.UUID) ([]ListExtractedByJobIDRow, error) {
+	rows, err := q.db.Query(ctx, listExtractedByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListExtractedByJobIDRow
+	for rows.Next() {
+		var i ListExtractedByJobIDRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.Extracted); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPagesForIndex = `-- name: ListPagesForIndex :many
-SELECT id,title, text_content FROM pages
+SELECT id,title, text_content, main_content, url, canonical_url FROM pages
 `
 
 type ListPagesForIndexRow struct {
-	ID          int32       `json:"id"`
-	Title       pgtype.Text `json:"title"`
-	TextContent string      `json:"text_content"`
+	ID           int32       `json:"id"`
+	Title        pgtype.Text `json:"title"`
+	TextContent  string      `json:"text_content"`
+	MainContent  string      `json:"main_content"`
+	Url          string      `json:"url"`
+	CanonicalUrl pgtype.Text `json:"canonical_url"`
 }
 
 func (q *Queries) ListPagesForIndex(ctx context.Context) ([]ListPagesForIndexRow, error) {
@@ -62,7 +110,44 @@ func (q *Queries) ListPagesForIndex(ctx context.Context) ([]ListPagesForIndexRow
 	var items []ListPagesForIndexRow
 	for rows.Next() {
 		var i ListPagesForIndexRow
-		if err := rows.Scan(&i.ID, &i.Title, &i.TextContent); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.TextContent,
+			&i.MainContent,
+			&i.Url,
+			&i.CanonicalUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTablesByJobID = `-- name: ListTablesByJobID :many
+SELECT id, url, tables FROM pages WHERE job_id = $1 AND tables IS NOT NULL ORDER BY id
+`
+
+type ListTablesByJobIDRow struct {
+	ID     int32  `json:"id"`
+	Url    string `json:"url"`
+	Tables []byte `json:"tables"`
+}
+
+func (q *Queries) ListTablesByJobID(ctx context.Context, jobID pgtype.UUID) ([]ListTablesByJobIDRow, error) {
+	rows, err := q.db.Query(ctx, listTablesByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTablesByJobIDRow
+	for rows.Next() {
+		var i ListTablesByJobIDRow
+		if err := rows.Scan(&i.ID, &i.Url, &i.Tables); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -74,23 +159,53 @@ func (q *Queries) ListPagesForIndex(ctx context.Context) ([]ListPagesForIndexRow
 }
 
 const upsertPage = `-- name: UpsertPage :one
-INSERT INTO pages (job_id, url, title, html, text_content)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO pages (job_id, url, title, html, text_content, main_content, stats, status_code, final_url, content_type, charset, headers, content_length, fetch_duration_ms, content_hash, redirect_chain, canonical_url, metadata, extracted, tables)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 ON CONFLICT (url) DO UPDATE SET
 job_id = EXCLUDED.job_id,
 title = EXCLUDED.title,
 html = EXCLUDED.html,
 text_content = EXCLUDED.text_content,
+main_content = EXCLUDED.main_content,
+stats = EXCLUDED.stats,
+status_code = EXCLUDED.status_code,
+final_url = EXCLUDED.final_url,
+content_type = EXCLUDED.content_type,
+charset = EXCLUDED.charset,
+headers = EXCLUDED.headers,
+content_length = EXCLUDED.content_length,
+fetch_duration_ms = EXCLUDED.fetch_duration_ms,
+content_hash = EXCLUDED.content_hash,
+redirect_chain = EXCLUDED.redirect_chain,
+canonical_url = EXCLUDED.canonical_url,
+metadata = EXCLUDED.metadata,
+extracted = EXCLUDED.extracted,
+tables = EXCLUDED.tables,
 fetched_at = NOW()
-RETURNING id, job_id, url, title, html, text_content, fetched_at
+RETURNING id, job_id, url, title, html, text_content, fetched_at, stats, status_code, final_url, content_type, charset, headers, content_length, fetch_duration_ms, content_hash, redirect_chain, main_content, canonical_url, metadata, extracted, tables
 `
 
 type UpsertPageParams struct {
-	JobID       pgtype.UUID `json:"job_id"`
-	Url         string      `json:"url"`
-	Title       pgtype.Text `json:"title"`
-	Html        string      `json:"html"`
-	TextContent string      `json:"text_content"`
+	JobID           pgtype.UUID `json:"job_id"`
+	Url             string      `json:"url"`
+	Title           pgtype.Text `json:"title"`
+	Html            string      `json:"html"`
+	TextContent     string      `json:"text_content"`
+	MainContent     string      `json:"main_content"`
+	Stats           []byte      `json:"stats"`
+	StatusCode      pgtype.Int4 `json:"status_code"`
+	FinalUrl        pgtype.Text `json:"final_url"`
+	ContentType     pgtype.Text `json:"content_type"`
+	Charset         pgtype.Text `json:"charset"`
+	Headers         []byte      `json:"headers"`
+	ContentLength   pgtype.Int8 `json:"content_length"`
+	FetchDurationMs pgtype.Int4 `json:"fetch_duration_ms"`
+	ContentHash     pgtype.Text `json:"content_hash"`
+	RedirectChain   []byte      `json:"redirect_chain"`
+	CanonicalUrl    pgtype.Text `json:"canonical_url"`
+	Metadata        []byte      `json:"metadata"`
+	Extracted       []byte      `json:"extracted"`
+	Tables          []byte      `json:"tables"`
 }
 
 func (q *Queries) UpsertPage(ctx context.Context, arg UpsertPageParams) (Page, error) {
@@ -100,6 +215,21 @@ func (q *Queries) UpsertPage(ctx context.Context, arg UpsertPageParams) (Page, e
 		arg.Title,
 		arg.Html,
 		arg.TextContent,
+		arg.MainContent,
+		arg.Stats,
+		arg.StatusCode,
+		arg.FinalUrl,
+		arg.ContentType,
+		arg.Charset,
+		arg.Headers,
+		arg.ContentLength,
+		arg.FetchDurationMs,
+		arg.ContentHash,
+		arg.RedirectChain,
+		arg.CanonicalUrl,
+		arg.Metadata,
+		arg.Extracted,
+		arg.Tables,
 	)
 	var i Page
 	err := row.Scan(
@@ -110,6 +240,21 @@ func (q *Queries) UpsertPage(ctx context.Context, arg UpsertPageParams) (Page, e
 		&i.Html,
 		&i.TextContent,
 		&i.FetchedAt,
+		&i.Stats,
+		&i.StatusCode,
+		&i.FinalUrl,
+		&i.ContentType,
+		&i.Charset,
+		&i.Headers,
+		&i.ContentLength,
+		&i.FetchDurationMs,
+		&i.ContentHash,
+		&i.RedirectChain,
+		&i.MainContent,
+		&i.CanonicalUrl,
+		&i.Metadata,
+		&i.Extracted,
+		&i.Tables,
 	)
 	return i, err
 }
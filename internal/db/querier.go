@@ -11,12 +11,32 @@ import (
 )
 
 type Querier interface {
+	AddFrontierURL(ctx context.Context, arg AddFrontierURLParams) error
+	CancelPendingJob(ctx context.Context, id pgtype.UUID) (int64, error)
+	ClaimNextPendingJob(ctx context.Context, ownerInstance pgtype.Text) (Job, error)
+	CountPendingJobsAhead(ctx context.Context, arg CountPendingJobsAheadParams) (int64, error)
+	CreateFetch(ctx context.Context, arg CreateFetchParams) error
 	CreateJob(ctx context.Context, arg CreateJobParams) (Job, error)
+	CreateLink(ctx context.Context, arg CreateLinkParams) error
+	CreateSkippedURL(ctx context.Context, arg CreateSkippedURLParams) error
 	GetAllJobs(ctx context.Context) ([]Job, error)
+	GetCrawlPath(ctx context.Context, arg GetCrawlPathParams) ([]GetCrawlPathRow, error)
+	GetCrawlTree(ctx context.Context, jobID pgtype.UUID) ([]GetCrawlTreeRow, error)
+	GetFrontierByJobID(ctx context.Context, jobID pgtype.UUID) ([]Frontier, error)
 	GetJob(ctx context.Context, id pgtype.UUID) (Job, error)
 	GetPagesByJobID(ctx context.Context, jobID pgtype.UUID) ([]Page, error)
+	GetSkippedURLsByJobID(ctx context.Context, jobID pgtype.UUID) ([]SkippedUrl, error)
+	ListExtractedByJobID(ctx context.Context, jobID pgtype.UUID) ([]ListExtractedByJobIDRow, error)
+	ListFetchesByJobID(ctx context.Context, arg ListFetchesByJobIDParams) ([]Fetch, error)
+	ListInboundLinks(ctx context.Context, arg ListInboundLinksParams) ([]Link, error)
+	ListOutboundLinks(ctx context.Context, arg ListOutboundLinksParams) ([]Link, error)
 	ListPagesForIndex(ctx context.Context) ([]ListPagesForIndexRow, error)
+	ListTablesByJobID(ctx context.Context, jobID pgtype.UUID) ([]ListTablesByJobIDRow, error)
+	ReleaseOrphanedJob(ctx context.Context, arg ReleaseOrphanedJobParams) (int64, error)
+	RetryFrontierURL(ctx context.Context, arg RetryFrontierURLParams) error
 	TryIncrementPagesCrawled(ctx context.Context, arg TryIncrementPagesCrawledParams) (Job, error)
+	UpdateFrontierState(ctx context.Context, arg UpdateFrontierStateParams) error
+	UpdateJobHeartbeat(ctx context.Context, arg UpdateJobHeartbeatParams) error
 	UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams) (Job, error)
 	UpsertPage(ctx context.Context, arg UpsertPageParams) (Page, error)
 }
@@ -1,9 +1,16 @@
 package http
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"go-crawler/internal/model"
+	"go-crawler/internal/service"
+	"go-crawler/internal/urlnorm"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 func (s *Server) handleCrawl(w http.ResponseWriter, r *http.Request) {
@@ -20,6 +27,10 @@ func (s *Server) handleCrawl(w http.ResponseWriter, r *http.Request) {
 
 	job, err := s.service.Submit(r.Context(), input)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -52,6 +63,38 @@ func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(job)
 }
 
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.service.Cancel(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotActive) || errors.Is(err, service.ErrJobRunningElsewhere) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrJobNotFound) {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		fmt.Println("[http] Error cancelling job:", err)
+		http.Error(w, "could not cancel job", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
 func (s *Server) handleGetPages(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -75,6 +118,269 @@ func (s *Server) handleGetPages(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(pages)
 }
 
+func (s *Server) handleGetSkipped(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	skipped, err := s.Repository.GetSkippedURLsByJobID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "skipped URLs not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(skipped)
+}
+
+// handleGetFetches returns a job's fetch log. Query parameters narrow it: url (substring),
+// status, error_class, failed=true (only network and HTTP errors, see
+// model.FetchFailureClasses) and limit.
+func (s *Server) handleGetFetches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := model.FetchFilter{
+		URL:        q.Get("url"),
+		ErrorClass: model.FetchErrorClass(q.Get("error_class")),
+	}
+	var err error
+	if v := q.Get("status"); v != "" {
+		if filter.StatusCode, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "status must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("failed"); v != "" {
+		if filter.FailedOnly, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "failed must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	fetches, err := s.Repository.ListFetches(r.Context(), id, filter)
+	if err != nil {
+		http.Error(w, "fetches not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(fetches)
+}
+
+// handleGetExtracted downloads the extraction rule results of a job's pages as JSON
+// Lines, one page per line.
+func (s *Server) handleGetExtracted(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	records, err := s.Repository.ListExtracted(r.Context(), id)
+	if err != nil {
+		http.Error(w, "extracted data not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-extracted.jsonl"`, id))
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return
+		}
+	}
+}
+
+// handleGetTables downloads the tables of a job's pages as CSV, one record per cell:
+// the page URL, the table's 1-based number on the page, its caption, the row ("header"
+// for the column names, else the 1-based data row), the 1-based column and the value.
+func (s *Server) handleGetTables(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	pages, err := s.Repository.ListTables(r.Context(), id)
+	if err != nil {
+		http.Error(w, "tables not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-tables.csv"`, id))
+	w.WriteHeader(http.StatusOK)
+	if err := writeTablesCSV(w, pages); err != nil {
+		fmt.Println("[http] Error writing tables:", err)
+	}
+}
+
+// writeTablesCSV writes the records of handleGetTables to w, stopping at the first
+// write error.
+func writeTablesCSV(w io.Writer, pages []*model.PageTables) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"page_url", "table", "caption", "row", "col", "value"}); err != nil {
+		return err
+	}
+	for _, page := range pages {
+		for t, table := range page.Tables {
+			write := func(row string, cells []string) error {
+				for c, value := range cells {
+					if err := cw.Write([]string{page.URL, strconv.Itoa(t + 1), table.Caption, row, strconv.Itoa(c + 1), value}); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if err := write("header", table.Header); err != nil {
+				return err
+			}
+			for i, cells := range table.Rows {
+				if err := write(strconv.Itoa(i+1), cells); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// handleGetLinks lists the links of one page of a job: those found on it by default,
+// or those pointing at it with direction=in. url is normalized as the crawl normalized
+// the URLs it stored, so it need not be given in that exact form.
+func (s *Server) handleGetLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("url") == "" {
+		http.Error(w, "url is required", http.StatusBadRequest)
+		return
+	}
+	pageURL, ok := s.jobURL(w, r, id, q.Get("url"))
+	if !ok {
+		return
+	}
+
+	var links []*model.LinkEdge
+	var err error
+	switch q.Get("direction") {
+	case "", "out":
+		links, err = s.Repository.ListOutboundLinks(r.Context(), id, pageURL)
+	case "in":
+		links, err = s.Repository.ListInboundLinks(r.Context(), id, pageURL)
+	default:
+		http.Error(w, "direction must be in or out", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "links not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+// handleGetTree returns a job's crawl tree, shallowest URLs first. With url set (and
+// normalized as in handleGetLinks) it returns only the path from the start URL down to
+// that URL.
+func (s *Server) handleGetTree(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Job ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var nodes []*model.CrawlTreeNode
+	var err error
+	if rawURL := r.URL.Query().Get("url"); rawURL != "" {
+		pageURL, ok := s.jobURL(w, r, id, rawURL)
+		if !ok {
+			return
+		}
+		nodes, err = s.Repository.CrawlPath(r.Context(), id, pageURL)
+	} else {
+		nodes, err = s.Repository.CrawlTree(r.Context(), id)
+	}
+	if err != nil {
+		http.Error(w, "crawl tree not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(nodes)
+}
+
+// jobURL normalizes rawURL with the normalizer of job id's crawl, so it matches the
+// URLs stored for the job. On failure it writes the error response and returns false.
+func (s *Server) jobURL(w http.ResponseWriter, r *http.Request, id, rawURL string) (string, bool) {
+	job, err := s.Repository.GetJob(r.Context(), id)
+	if err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return "", false
+	}
+	pageURL, err := urlnorm.Default.With(job.Input.StripQueryParams).Normalize(rawURL)
+	if err != nil {
+		http.Error(w, "invalid url", http.StatusBadRequest)
+		return "", false
+	}
+	return pageURL, true
+}
+
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -99,7 +405,7 @@ func (s *Server) handleReindex(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	pages, err := s.Repository.ListPagesForIndex(r.Context())
+	pages, err := s.Repository.ListPagesForIndex(r.Context(), s.mainContentWeight)
 	if err != nil {
 		http.Error(w, "Failed to list pages for index", http.StatusInternalServerError)
 		return
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"go-crawler/internal/model"
+)
+
+func TestWriteTablesCSV(t *testing.T) {
+	pages := []*model.PageTables{
+		{URL: "http://example.com/a", Tables: []model.Table{
+			{Caption: "Prices, 2026", Header: []string{"name", "price"}, Rows: [][]string{{"a", "1"}, {"b", ""}}},
+			{Rows: [][]string{{"x"}}},
+		}},
+		{URL: "http://example.com/b", Tables: []model.Table{{Rows: [][]string{{"p", "q", "r"}}}}},
+	}
+	var buf bytes.Buffer
+	if err := writeTablesCSV(&buf, pages); err != nil {
+		t.Fatal(err)
+	}
+	want := `page_url,table,caption,row,col,value
+http://example.com/a,1,"Prices, 2026",header,1,name
+http://example.com/a,1,"Prices, 2026",header,2,price
+http://example.com/a,1,"Prices, 2026",1,1,a
+http://example.com/a,1,"Prices, 2026",1,2,1
+http://example.com/a,1,"Prices, 2026",2,1,b
+http://example.com/a,1,"Prices, 2026",2,2,
+http://example.com/a,2,,1,1,x
+http://example.com/b,1,,1,1,p
+http://example.com/b,1,,1,2,q
+http://example.com/b,1,,1,3,r
+`
+	if got := buf.String(); got != want {
+		t.Errorf("CSV:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// failingWriter accepts n bytes, then fails every write.
+type failingWriter struct {
+	n      int
+	writes int
+}
+
+var errWrite = errors.New("connection closed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteTablesCSVWriteError(t *testing.T) {
+	rows := make([][]string, 10000)
+	for i := range rows {
+		rows[i] = []string{"cell"}
+	}
+	pages := []*model.PageTables{{URL: "http://example.com/", Tables: []model.Table{{Rows: rows}}}}
+
+	w := &failingWriter{n: 100}
+	if err := writeTablesCSV(w, pages); !errors.Is(err, errWrite) {
+		t.Errorf("writeTablesCSV = %v, want %v", err, errWrite)
+	}
+	if w.writes != 1 {
+		t.Errorf("%d writes after the first error, want none", w.writes-1)
+	}
+
+	w = &failingWriter{n: 10}
+	if err := writeTablesCSV(w, pages[:0]); !errors.Is(err, errWrite) {
+		t.Errorf("writeTablesCSV flushing the header = %v, want %v", err, errWrite)
+	}
+}
@@ -12,17 +12,29 @@ type Server struct {
 	service    *service.CrawlService
 	index      *search.Index
 	Repository *repository.Repository
+	// mainContentWeight is the main content weight the index is built with, so a
+	// reindex scores pages as the indexing writer did.
+	mainContentWeight float64
 }
 
-func NewServer(svc *service.CrawlService, idx *search.Index, repo *repository.Repository) *Server {
+func NewServer(svc *service.CrawlService, idx *search.Index, repo *repository.Repository, mainContentWeight float64) *Server {
 	server := &Server{
-		router:  http.NewServeMux(),
-		service: svc,
-		index:   idx,
+		router:            http.NewServeMux(),
+		service:           svc,
+		index:             idx,
+		Repository:        repo,
+		mainContentWeight: mainContentWeight,
 	}
 	server.router.HandleFunc("/crawl", server.handleCrawl)
 	server.router.HandleFunc("/crawl/{id}", server.handleGetJob)
+	server.router.HandleFunc("DELETE /crawl/{id}", server.handleCancelJob)
 	server.router.HandleFunc("/crawl/{id}/pages", server.handleGetPages)
+	server.router.HandleFunc("/crawl/{id}/skipped", server.handleGetSkipped)
+	server.router.HandleFunc("/crawl/{id}/fetches", server.handleGetFetches)
+	server.router.HandleFunc("/crawl/{id}/extracted", server.handleGetExtracted)
+	server.router.HandleFunc("/crawl/{id}/tables", server.handleGetTables)
+	server.router.HandleFunc("/crawl/{id}/links", server.handleGetLinks)
+	server.router.HandleFunc("/crawl/{id}/tree", server.handleGetTree)
 	server.router.HandleFunc("/reindex", server.handleReindex)
 	server.router.HandleFunc("/search", server.handleSearch)
 	return server
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type CrawlStatus string
 
@@ -12,12 +15,150 @@ const (
 	CrawlStatusFailed    CrawlStatus = "FAILED"
 )
 
+// ScopeMode selects which discovered links a crawl may follow.
+type ScopeMode string
+
+const (
+	// ScopeHost follows links on the start URL's exact host (and port) only.
+	ScopeHost ScopeMode = "HOST"
+	// ScopeDomain follows links on the start URL's registrable domain and its subdomains.
+	ScopeDomain ScopeMode = "DOMAIN"
+	// ScopeAllowedDomains follows links on the domains in AllowedDomains and their subdomains.
+	ScopeAllowedDomains ScopeMode = "ALLOWED_DOMAINS"
+	// ScopeUnrestricted follows any http or https link.
+	ScopeUnrestricted ScopeMode = "UNRESTRICTED"
+)
+
+// URLRuleAction says whether a matching link is followed or dropped.
+type URLRuleAction string
+
+const (
+	URLRuleInclude URLRuleAction = "INCLUDE"
+	URLRuleExclude URLRuleAction = "EXCLUDE"
+)
+
+// URLRuleSyntax is the pattern language of a URLRule.
+type URLRuleSyntax string
+
+const (
+	// URLRuleGlob patterns must match the whole path plus "?query";
+	// "**" matches anything and "*" anything except "/".
+	URLRuleGlob URLRuleSyntax = "GLOB"
+	// URLRuleRegex patterns match anywhere in the path plus "?query".
+	URLRuleRegex URLRuleSyntax = "REGEX"
+)
+
+// URLRule is one ordered include/exclude rule applied to discovered links.
+// The first matching rule wins; Syntax defaults to GLOB.
+type URLRule struct {
+	Action  URLRuleAction
+	Syntax  URLRuleSyntax
+	Pattern string
+}
+
 type CrawlInput struct {
 	StartURL       string
 	MaxDepth       int
 	MaxPages       int
 	SameDomainOnly bool
-	RequestDelayMs int
+	// Scope overrides SameDomainOnly when set. Empty means DOMAIN if SameDomainOnly, else HOST.
+	Scope          ScopeMode
+	AllowedDomains []string
+	// URLRules filter links before they are enqueued. If any INCLUDE rule exists,
+	// links matching no rule are dropped.
+	URLRules []URLRule
+	// StripQueryParams are removed from URLs before dedupe, in addition to the
+	// default tracking parameters (utm_*, gclid, fbclid, ...). A trailing "*" matches a prefix.
+	StripQueryParams []string
+	RequestDelayMs   int
+	// MaxConcurrentPerHost caps in-flight requests to one host (0 = default of 2).
+	MaxConcurrentPerHost int
+	// IgnoreRobots disables robots.txt checks; only for sites we own.
+	IgnoreRobots bool
+	// Priority orders the PENDING queue: higher runs first, ties run oldest first.
+	Priority int
+	// MaxAttempts caps fetch attempts per URL for transient failures (429, 5xx,
+	// timeouts, connection resets); 0 uses the engine default.
+	MaxAttempts int
+	// AllowedContentTypes lists the media types that are read and parsed ("text/*"
+	// style wildcards allowed); empty means HTML only. Responses without a usable
+	// Content-Type are sniffed.
+	AllowedContentTypes []string
+	// MaxBodyBytes cuts off larger responses; 0 uses the engine default.
+	MaxBodyBytes int64
+	// HeadRequest probes each URL with HEAD first, so disallowed or oversized
+	// responses are rejected without downloading them.
+	HeadRequest bool
+	// LinkSources selects the elements whose links are followed; empty means <a href> only.
+	LinkSources []LinkSource
+	// RobotsMeta says which page-level robots directives (<meta name="robots">,
+	// X-Robots-Tag) and rel="nofollow" links are honored; empty means RESPECT.
+	RobotsMeta RobotsMetaPolicy
+	// FollowCanonical enqueues each page's rel="canonical" target, if in scope and not
+	// yet discovered, at the page's own depth.
+	FollowCanonical bool
+	// ExtractionRules pull named values out of every stored page into Page.Extracted.
+	ExtractionRules []ExtractionRule
+}
+
+// ExtractionMode is what an extraction rule takes from the elements it matches.
+type ExtractionMode string
+
+const (
+	ExtractText      ExtractionMode = "TEXT"      // visible text, the default
+	ExtractAttribute ExtractionMode = "ATTRIBUTE" // value of the rule's Attribute
+)
+
+// ExtractionRule selects elements with a CSS selector and extracts their text or an
+// attribute under Name. Without Multiple only the first match is kept (null if none);
+// with it, every match is kept as a list.
+type ExtractionRule struct {
+	Name      string
+	Selector  string
+	Mode      ExtractionMode
+	Attribute string
+	Multiple  bool
+}
+
+// RobotsMetaPolicy is a job's override for noindex and nofollow directives.
+type RobotsMetaPolicy string
+
+const (
+	// RobotsMetaRespect fetches noindex pages without storing them and follows no
+	// links from nofollow pages or rel="nofollow" links.
+	RobotsMetaRespect RobotsMetaPolicy = "RESPECT"
+	// RobotsMetaIgnoreNoIndex stores every page but still honors nofollow.
+	RobotsMetaIgnoreNoIndex RobotsMetaPolicy = "IGNORE_NOINDEX"
+	// RobotsMetaIgnoreNoFollow follows every link but still honors noindex.
+	RobotsMetaIgnoreNoFollow RobotsMetaPolicy = "IGNORE_NOFOLLOW"
+	// RobotsMetaIgnore ignores both; only for sites we own.
+	RobotsMetaIgnore RobotsMetaPolicy = "IGNORE"
+)
+
+// LinkSource is the kind of element a link was discovered in.
+type LinkSource string
+
+const (
+	LinkSourceAnchor LinkSource = "A"    // <a href>
+	LinkSourceArea   LinkSource = "AREA" // <area href> in image maps
+	// LinkSourceLink is <link href> with rel alternate, next or prev.
+	LinkSourceLink   LinkSource = "LINK"
+	LinkSourceIframe LinkSource = "IFRAME"
+	LinkSourceFrame  LinkSource = "FRAME"
+	// LinkSourceForm is the action of a <form> submitted with GET.
+	LinkSourceForm LinkSource = "FORM"
+	// LinkSourceSrcset is each candidate URL in an <img> or <source> srcset.
+	LinkSourceSrcset LinkSource = "SRCSET"
+)
+
+// Link is a link discovered on a page, resolved to an absolute http(s) URL.
+// Rel holds the element's lowercased rel keywords; Text is the anchor text, or the
+// alt or title text for elements without content.
+type Link struct {
+	URL    string
+	Source LinkSource
+	Rel    []string
+	Text   string
 }
 
 type CrawlJob struct {
@@ -27,6 +168,13 @@ type CrawlJob struct {
 
 	PagesCrawled int
 	Error        string
+	// QueuePosition is the 1-based position among PENDING jobs; 0 once the job has left the queue.
+	QueuePosition int
+
+	// OwnerInstance is the server instance running the job; HeartbeatAt is refreshed
+	// while it runs. Together they let a restarted server detect orphaned jobs.
+	OwnerInstance string
+	HeartbeatAt   time.Time
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -35,6 +183,32 @@ type CrawlJob struct {
 type URLTask struct {
 	URL   string
 	Depth int
+	// DiscoveredFrom is the URL of the page the link was found on; empty for the start URL.
+	DiscoveredFrom string
+	// Attempt is the number of failed fetch attempts so far; NotBefore is when a retry may start.
+	Attempt   int
+	NotBefore time.Time
+}
+
+// FrontierState is the progress of a URL in a job's persisted frontier.
+type FrontierState string
+
+const (
+	FrontierQueued FrontierState = "QUEUED"
+	FrontierDone   FrontierState = "DONE"
+	// FrontierFailed means the URL still failed after the job's MaxAttempts.
+	FrontierFailed FrontierState = "FAILED"
+)
+
+// FrontierEntry is a URL a job has discovered, persisted so an interrupted crawl can resume.
+type FrontierEntry struct {
+	JobID          string
+	URL            string
+	Depth          int
+	State          FrontierState
+	DiscoveredFrom string
+	Attempts       int
+	NextAttemptAt  time.Time
 }
 
 type Page struct {
@@ -44,9 +218,223 @@ type Page struct {
 	Title       string
 	Html        string
 	TextContent string
+	MainContent string // main article body; empty if the page has none
+	FetchedAt   time.Time
+	Stats       PageStats
+
+	// Response metadata.
+	StatusCode      int
+	FinalURL        string // URL that served the content, after redirects
+	ContentType     string // media type without parameters, e.g. "text/html"
+	Charset         string // encoding the body was transcoded to UTF-8 from
+	Headers         map[string][]string
+	ContentLength   int64 // body size in bytes
+	FetchDurationMs int
+	ContentHash     string // hex SHA-256 of the body
+	// RedirectChain lists the redirects followed from the requested URL to FinalURL.
+	RedirectChain []RedirectHop
+	// CanonicalURL is the normalized <link rel="canonical"> target, if the page declares
+	// one; pages sharing it are one document in the search index.
+	CanonicalURL string
+	// Metadata is what the page says about itself in <html lang>, <meta> tags,
+	// hreflang links and JSON-LD.
+	Metadata PageMetadata
+	// Extracted holds the job's ExtractionRules results by rule name: a string, nil, or
+	// a list of strings for rules with Multiple. Nil when the job has no rules.
+	Extracted map[string]any
+	// Tables are the page's HTML tables as rows of cells.
+	Tables []Table
+}
+
+// Table is an HTML table laid out as a grid: a cell spanning several rows or columns
+// has its text in each of them, and every row is as wide as the widest. Header holds
+// the column names from the header rows (several header rows joined with " / "), or
+// is nil when the table has none.
+type Table struct {
+	Caption string
+	Header  []string
+	Rows    [][]string
+}
+
+// PageTables is one page's tables, as downloaded for a job.
+type PageTables struct {
+	PageID int
+	URL    string
+	Tables []Table
+}
+
+// ExtractedRecord is one page's extraction results, as downloaded for a job.
+type ExtractedRecord struct {
+	PageID int
+	URL    string
+	Data   map[string]any
+}
+
+// PageMetadata is a page's structured metadata. OpenGraph holds og:* properties (and
+// article:*, book:*, profile:*, music:* and video:* ones), Twitter holds twitter:*
+// cards, each keyed by full property name with one value per tag. JSONLD holds each
+// parsed application/ld+json block.
+type PageMetadata struct {
+	Description string
+	Keywords    []string
+	Lang        string
+	OpenGraph   map[string][]string
+	Twitter     map[string][]string
+	Hreflang    []HreflangLink
+	JSONLD      []any
+}
+
+// HreflangLink is a <link rel="alternate" hreflang> pointing at a translation of a page.
+type HreflangLink struct {
+	Lang string
+	URL  string
+}
+
+// RedirectHop is one redirect followed while fetching a page.
+type RedirectHop struct {
+	From       string
+	To         string
+	StatusCode int
+}
+
+// PageStats summarises how the links found on a page were handled.
+type PageStats struct {
+	LinksFound    int
+	LinksAccepted int // passed scope and URL rules; may still be dropped as already visited
+	RejectedLinks []RejectedLink
+}
+
+// RejectedLink is a discovered link that was not enqueued and why.
+// Rule names the URL rule that rejected it, if any.
+type RejectedLink struct {
+	URL    string
+	Reason SkipReason
+	Rule   string
+}
+
+// SkipReason explains why a URL was not fetched.
+type SkipReason string
+
+const (
+	SkipReasonRobots     SkipReason = "ROBOTS_DISALLOWED"
+	SkipReasonOutOfScope SkipReason = "OUT_OF_SCOPE"
+	SkipReasonURLRule    SkipReason = "URL_RULE"
+	// SkipReasonNoInclude means the job has INCLUDE rules and none matched.
+	SkipReasonNoInclude SkipReason = "NO_INCLUDE_MATCH"
+	// SkipReasonFetchFailed means every allowed fetch attempt failed.
+	SkipReasonFetchFailed SkipReason = "FETCH_FAILED"
+	SkipReasonContentType SkipReason = "CONTENT_TYPE"
+	SkipReasonTooLarge    SkipReason = "BODY_TOO_LARGE"
+	// SkipReasonNoFollow means the link has rel="nofollow" or its page is nofollow.
+	SkipReasonNoFollow SkipReason = "NOFOLLOW"
+)
+
+// Fetch is one HTTP request made by a crawl, successful or not, kept so a missing
+// page can be traced: page GETs, HEAD probes and robots.txt requests. StatusCode is 0
+// when no response was received.
+type Fetch struct {
+	ID          int
+	JobID       string
+	URL         string
+	Method      string
+	Attempt     int // 1 for the first request of a URL, 2 for its first retry, ...
+	StatusCode  int
+	LatencyMs   int
+	Bytes       int
+	ContentType string
+	ErrorClass  FetchErrorClass // empty when the page was fetched and saved
+	Error       string
 	FetchedAt   time.Time
 }
 
+// FetchErrorClass groups why a fetch did not produce a saved page.
+type FetchErrorClass string
+
+const (
+	FetchErrorTimeout     FetchErrorClass = "TIMEOUT"
+	FetchErrorConnReset   FetchErrorClass = "CONNECTION_RESET"
+	FetchErrorDNS         FetchErrorClass = "DNS"
+	FetchErrorTLS         FetchErrorClass = "TLS"
+	FetchErrorNetwork     FetchErrorClass = "NETWORK" // any other transport failure, e.g. connection refused
+	FetchErrorCancelled   FetchErrorClass = "CANCELLED"
+	FetchErrorHTTPStatus  FetchErrorClass = "HTTP_STATUS"  // a response other than 200
+	FetchErrorRedirect    FetchErrorClass = "REDIRECT"     // redirect out of scope or disallowed
+	FetchErrorDuplicate   FetchErrorClass = "DUPLICATE"    // redirected to a URL the job already crawls
+	FetchErrorContentType FetchErrorClass = "CONTENT_TYPE" // media type not allowed by the job
+	FetchErrorTooLarge    FetchErrorClass = "TOO_LARGE"    // body over the job's MaxBodyBytes; cut off
+	FetchErrorParse       FetchErrorClass = "PARSE"
+	FetchErrorMaxPages    FetchErrorClass = "MAX_PAGES" // fetched after the job hit MaxPages; not saved
+	FetchErrorStore       FetchErrorClass = "STORE"     // the page could not be saved
+	FetchErrorNoIndex     FetchErrorClass = "NOINDEX"   // the page asked not to be indexed; not saved
+
+	// FetchErrorTooManyRedirects means the request was stopped after too many redirect hops.
+	FetchErrorTooManyRedirects FetchErrorClass = "TOO_MANY_REDIRECTS"
+)
+
+// FetchFailureClasses are the classes of requests that failed on the network or at the
+// server. The other classes are requests the job itself dropped (MAX_PAGES, NOINDEX,
+// DUPLICATE, CONTENT_TYPE, ...) or could not finish (CANCELLED, STORE, PARSE).
+var FetchFailureClasses = []FetchErrorClass{
+	FetchErrorTimeout,
+	FetchErrorConnReset,
+	FetchErrorDNS,
+	FetchErrorTLS,
+	FetchErrorNetwork,
+	FetchErrorHTTPStatus,
+	FetchErrorTooManyRedirects,
+}
+
+// Failed reports whether c is one of FetchFailureClasses.
+func (c FetchErrorClass) Failed() bool {
+	return slices.Contains(FetchFailureClasses, c)
+}
+
+// FetchFilter narrows a job's fetch log. Zero fields do not filter.
+type FetchFilter struct {
+	URL        string // substring of the URL
+	StatusCode int
+	ErrorClass FetchErrorClass
+	FailedOnly bool // only fetches whose class is in FetchFailureClasses
+	Limit      int
+}
+
+// LinkEdge is a link the job followed: from a page it fetched to a URL it accepted (in
+// scope, allowed by its URL rules, not nofollow) and queued, or had already queued.
+// Links on pages at MaxDepth are not followed, so not recorded. Depth is the depth the
+// target has when reached through this link.
+type LinkEdge struct {
+	ID           int
+	JobID        string
+	SourceURL    string
+	TargetURL    string
+	AnchorText   string
+	Rel          []string
+	Element      LinkSource
+	Depth        int
+	DiscoveredAt time.Time
+}
+
+// CrawlTreeNode is a URL in a job's crawl tree: the frontier entry with the page it was
+// first discovered on and the anchor text of that link. DiscoveredFrom is empty for
+// the start URL; AnchorText is empty when the URL was reached without a recorded link
+// (the start URL, a redirect target or a canonical target).
+type CrawlTreeNode struct {
+	URL            string
+	Depth          int
+	DiscoveredFrom string
+	State          FrontierState
+	AnchorText     string
+}
+
+// SkippedURL records a URL the engine decided not to fetch.
+type SkippedURL struct {
+	ID        int
+	JobID     string
+	URL       string
+	Reason    SkipReason
+	SkippedAt time.Time
+}
+
 // type IndexEntry struct {
 // 	Term   string
 // 	PageID string
@@ -0,0 +1,24 @@
+package model
+
+import "testing"
+
+func TestFetchErrorClassFailed(t *testing.T) {
+	failed := []FetchErrorClass{
+		FetchErrorTimeout, FetchErrorConnReset, FetchErrorDNS, FetchErrorTLS,
+		FetchErrorNetwork, FetchErrorHTTPStatus, FetchErrorTooManyRedirects,
+	}
+	dropped := []FetchErrorClass{
+		"", FetchErrorCancelled, FetchErrorRedirect, FetchErrorDuplicate, FetchErrorContentType,
+		FetchErrorTooLarge, FetchErrorParse, FetchErrorMaxPages, FetchErrorStore, FetchErrorNoIndex,
+	}
+	for _, c := range failed {
+		if !c.Failed() {
+			t.Errorf("%q.Failed() = false, want true", c)
+		}
+	}
+	for _, c := range dropped {
+		if c.Failed() {
+			t.Errorf("%q.Failed() = true, want false", c)
+		}
+	}
+}
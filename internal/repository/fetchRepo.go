@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"go-crawler/internal/db"
+	"go-crawler/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// defaultFetchLimit caps ListFetches when the filter sets no limit.
+const defaultFetchLimit = 1000
+
+func (r *Repository) RecordFetch(ctx context.Context, fetch *model.Fetch) error {
+	jid, err := uuidFromString(fetch.JobID)
+	if err != nil {
+		return err
+	}
+	return r.queries.CreateFetch(ctx, db.CreateFetchParams{
+		JobID:       jid,
+		Url:         fetch.URL,
+		Method:      fetch.Method,
+		Attempt:     int32(fetch.Attempt),
+		StatusCode:  int32(fetch.StatusCode),
+		LatencyMs:   int32(fetch.LatencyMs),
+		Bytes:       int64(fetch.Bytes),
+		ContentType: fetch.ContentType,
+		ErrorClass:  string(fetch.ErrorClass),
+		Error:       fetch.Error,
+	})
+}
+
+// ListFetches returns a job's fetch log in request order, narrowed by filter.
+func (r *Repository) ListFetches(ctx context.Context, jobID string, filter model.FetchFilter) ([]*model.Fetch, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultFetchLimit
+	}
+	failureClasses := make([]string, len(model.FetchFailureClasses))
+	for i, c := range model.FetchFailureClasses {
+		failureClasses[i] = string(c)
+	}
+	rows, err := r.queries.ListFetchesByJobID(ctx, db.ListFetchesByJobIDParams{
+		JobID:          jid,
+		Url:            pgtype.Text{String: filter.URL, Valid: filter.URL != ""},
+		StatusCode:     pgtype.Int4{Int32: int32(filter.StatusCode), Valid: filter.StatusCode != 0},
+		ErrorClass:     pgtype.Text{String: string(filter.ErrorClass), Valid: filter.ErrorClass != ""},
+		FailedOnly:     filter.FailedOnly,
+		FailureClasses: failureClasses,
+		RowLimit:       int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.Fetch, len(rows))
+	for i := range rows {
+		out[i] = dbFetchToModel(rows[i])
+	}
+	return out, nil
+}
+
+func dbFetchToModel(f db.Fetch) *model.Fetch {
+	return &model.Fetch{
+		ID:          int(f.ID),
+		JobID:       uuid.UUID(f.JobID.Bytes).String(),
+		URL:         f.Url,
+		Method:      f.Method,
+		Attempt:     int(f.Attempt),
+		StatusCode:  int(f.StatusCode),
+		LatencyMs:   int(f.LatencyMs),
+		Bytes:       int(f.Bytes),
+		ContentType: f.ContentType,
+		ErrorClass:  model.FetchErrorClass(f.ErrorClass),
+		Error:       f.Error,
+		FetchedAt:   f.FetchedAt.Time,
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go-crawler/internal/db"
+	"go-crawler/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestDBFetchToModel(t *testing.T) {
+	jobID := uuid.New()
+	fetchedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		got := dbFetchToModel(db.Fetch{
+			ID:          7,
+			JobID:       pgtype.UUID{Bytes: jobID, Valid: true},
+			Url:         "https://example.com/a",
+			Method:      method,
+			Attempt:     2,
+			StatusCode:  503,
+			LatencyMs:   120,
+			Bytes:       4096,
+			ContentType: "text/html",
+			ErrorClass:  string(model.FetchErrorHTTPStatus),
+			Error:       "503 Service Unavailable",
+			FetchedAt:   pgtype.Timestamptz{Time: fetchedAt, Valid: true},
+		})
+		want := model.Fetch{
+			ID:          7,
+			JobID:       jobID.String(),
+			URL:         "https://example.com/a",
+			Method:      method,
+			Attempt:     2,
+			StatusCode:  503,
+			LatencyMs:   120,
+			Bytes:       4096,
+			ContentType: "text/html",
+			ErrorClass:  model.FetchErrorHTTPStatus,
+			Error:       "503 Service Unavailable",
+			FetchedAt:   fetchedAt,
+		}
+		if *got != want {
+			t.Errorf("%s: got %+v, want %+v", method, *got, want)
+		}
+	}
+}
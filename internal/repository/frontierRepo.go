@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"go-crawler/internal/db"
+	"go-crawler/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AddFrontierTask persists a queued task. A URL already in the job's frontier is left unchanged.
+func (r *Repository) AddFrontierTask(ctx context.Context, jobID string, task *model.URLTask) error {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return err
+	}
+	return r.queries.AddFrontierURL(ctx, db.AddFrontierURLParams{
+		JobID:          jid,
+		Url:            task.URL,
+		Depth:          int32(task.Depth),
+		State:          string(model.FrontierQueued),
+		DiscoveredFrom: pgtype.Text{String: task.DiscoveredFrom, Valid: task.DiscoveredFrom != ""},
+	})
+}
+
+func (r *Repository) SetFrontierState(ctx context.Context, jobID string, url string, state model.FrontierState) error {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return err
+	}
+	return r.queries.UpdateFrontierState(ctx, db.UpdateFrontierStateParams{
+		State: string(state),
+		JobID: jid,
+		Url:   url,
+	})
+}
+
+// RetryFrontierTask puts a failed task back to QUEUED with its attempt count and the
+// earliest time it may be fetched again.
+func (r *Repository) RetryFrontierTask(ctx context.Context, jobID string, task *model.URLTask) error {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return err
+	}
+	return r.queries.RetryFrontierURL(ctx, db.RetryFrontierURLParams{
+		Attempts:      int32(task.Attempt),
+		NextAttemptAt: pgtype.Timestamptz{Time: task.NotBefore, Valid: !task.NotBefore.IsZero()},
+		JobID:         jid,
+		Url:           task.URL,
+	})
+}
+
+func (r *Repository) LoadFrontier(ctx context.Context, jobID string) ([]*model.FrontierEntry, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.GetFrontierByJobID(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.FrontierEntry, len(rows))
+	for i := range rows {
+		out[i] = &model.FrontierEntry{
+			JobID:          uuid.UUID(rows[i].JobID.Bytes).String(),
+			URL:            rows[i].Url,
+			Depth:          int(rows[i].Depth),
+			State:          model.FrontierState(rows[i].State),
+			DiscoveredFrom: rows[i].DiscoveredFrom.String,
+			Attempts:       int(rows[i].Attempts),
+			NextAttemptAt:  rows[i].NextAttemptAt.Time,
+		}
+	}
+	return out, nil
+}
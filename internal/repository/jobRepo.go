@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"time"
 
 	"go-crawler/internal/db"
 	"go-crawler/internal/model"
@@ -15,6 +16,7 @@ import (
 )
 
 var _ service.JobRepository = (*Repository)(nil)
+var _ service.OrphanRepository = (*Repository)(nil)
 
 func parseUUID(s string) (pgtype.UUID, error) {
 	u, err := uuid.Parse(s)
@@ -37,13 +39,15 @@ func dbJobToModel(j db.Job) (*model.CrawlJob, error) {
 		errStr = j.Error.String
 	}
 	return &model.CrawlJob{
-		ID:           idStr,
-		Input:        input,
-		Status:       model.CrawlStatus(j.Status),
-		PagesCrawled: int(j.PagesCrawled),
-		Error:        errStr,
-		CreatedAt:    j.CreatedAt.Time,
-		UpdatedAt:    j.UpdatedAt.Time,
+		ID:            idStr,
+		Input:         input,
+		Status:        model.CrawlStatus(j.Status),
+		PagesCrawled:  int(j.PagesCrawled),
+		Error:         errStr,
+		OwnerInstance: j.OwnerInstance.String,
+		HeartbeatAt:   j.HeartbeatAt.Time,
+		CreatedAt:     j.CreatedAt.Time,
+		UpdatedAt:     j.UpdatedAt.Time,
 	}, nil
 }
 
@@ -62,6 +66,7 @@ func (r *Repository) CreateJob(ctx context.Context, job *model.CrawlJob) error {
 		Status:       string(job.Status),
 		Error:        pgtype.Text{String: job.Error, Valid: job.Error != ""},
 		PagesCrawled: int32(job.PagesCrawled),
+		Priority:     int32(job.Input.Priority),
 		CreatedAt:    pgtype.Timestamptz{Time: job.CreatedAt, Valid: true},
 		UpdatedAt:    pgtype.Timestamptz{Time: job.UpdatedAt, Valid: true},
 	})
@@ -71,12 +76,12 @@ func (r *Repository) CreateJob(ctx context.Context, job *model.CrawlJob) error {
 func (r *Repository) GetJob(ctx context.Context, id string) (*model.CrawlJob, error) {
 	pid, err := parseUUID(id)
 	if err != nil {
-		return nil, err
+		return nil, service.ErrJobNotFound // a malformed ID cannot name a job
 	}
 	j, err := r.queries.GetJob(ctx, pid)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, errors.New("job not found")
+			return nil, service.ErrJobNotFound
 		}
 		return nil, err
 	}
@@ -96,6 +101,84 @@ func (r *Repository) UpdateJobStatus(ctx context.Context, id string, status mode
 	return err
 }
 
+// ClaimNextPendingJob atomically moves the highest-priority, oldest PENDING job to RUNNING
+// and records instanceID as its owner. Returns nil if the queue is empty.
+func (r *Repository) ClaimNextPendingJob(ctx context.Context, instanceID string) (*model.CrawlJob, error) {
+	j, err := r.queries.ClaimNextPendingJob(ctx, pgtype.Text{String: instanceID, Valid: instanceID != ""})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dbJobToModel(j)
+}
+
+// CancelPendingJob sets a job to CANCELLED only if it is still PENDING.
+// Returns false if the job is in any other state or does not exist.
+func (r *Repository) CancelPendingJob(ctx context.Context, id string) (bool, error) {
+	pid, err := parseUUID(id)
+	if err != nil {
+		return false, service.ErrJobNotFound
+	}
+	n, err := r.queries.CancelPendingJob(ctx, pid)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// ReleaseOrphanedJob moves a RUNNING job to status (PENDING or FAILED) only if it is
+// still orphaned when the update runs: it has no owner, is owned by ownInstance, or
+// its heartbeat is older than staleAfter. An empty ownInstance matches no owner.
+// It returns false if the job was left alone, e.g. because a live instance owns it.
+func (r *Repository) ReleaseOrphanedJob(ctx context.Context, id string, status model.CrawlStatus, errMsg, ownInstance string, staleAfter time.Duration) (bool, error) {
+	pid, err := parseUUID(id)
+	if err != nil {
+		return false, err
+	}
+	n, err := r.queries.ReleaseOrphanedJob(ctx, db.ReleaseOrphanedJobParams{
+		Status:      string(status),
+		Error:       pgtype.Text{String: errMsg, Valid: errMsg != ""},
+		ID:          pid,
+		OwnInstance: pgtype.Text{String: ownInstance, Valid: ownInstance != ""},
+		StaleAfter:  pgtype.Interval{Microseconds: staleAfter.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// GetQueuePosition returns the 1-based position of a PENDING job in the dispatch order.
+func (r *Repository) GetQueuePosition(ctx context.Context, job *model.CrawlJob) (int, error) {
+	pid, err := parseUUID(job.ID)
+	if err != nil {
+		return 0, err
+	}
+	ahead, err := r.queries.CountPendingJobsAhead(ctx, db.CountPendingJobsAheadParams{
+		Priority:  int32(job.Input.Priority),
+		CreatedAt: pgtype.Timestamptz{Time: job.CreatedAt, Valid: true},
+		ID:        pid,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(ahead) + 1, nil
+}
+
+// UpdateJobHeartbeat records that instanceID owns the job and is still alive.
+func (r *Repository) UpdateJobHeartbeat(ctx context.Context, id string, instanceID string) error {
+	pid, err := parseUUID(id)
+	if err != nil {
+		return err
+	}
+	return r.queries.UpdateJobHeartbeat(ctx, db.UpdateJobHeartbeatParams{
+		OwnerInstance: pgtype.Text{String: instanceID, Valid: instanceID != ""},
+		ID:            pid,
+	})
+}
+
 func (r *Repository) TryIncrementPagesCrawled(ctx context.Context, id string, max int) (bool, error) {
 	pid, err := parseUUID(id)
 	if err != nil {
@@ -113,3 +196,19 @@ func (r *Repository) TryIncrementPagesCrawled(ctx context.Context, id string, ma
 	}
 	return true, nil
 }
+
+func (r *Repository) GetAllJobs(ctx context.Context) ([]*model.CrawlJob, error) {
+	rows, err := r.queries.GetAllJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.CrawlJob, len(rows))
+	for i := range rows {
+		job, err := dbJobToModel(rows[i])
+		if err != nil {
+			return nil, err
+		}
+		out[i] = job
+	}
+	return out, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"go-crawler/internal/db"
+	"go-crawler/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// RecordLinks stores the edges found on one page in a single transaction. An edge the
+// job already has between the same two URLs is left unchanged.
+func (r *Repository) RecordLinks(ctx context.Context, jobID string, links []*model.LinkEdge) error {
+	if len(links) == 0 {
+		return nil
+	}
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return err
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := r.queries.WithTx(tx)
+	for _, l := range links {
+		if err := q.CreateLink(ctx, db.CreateLinkParams{
+			JobID:      jid,
+			SourceUrl:  l.SourceURL,
+			TargetUrl:  l.TargetURL,
+			AnchorText: l.AnchorText,
+			Rel:        strings.Join(l.Rel, " "),
+			Element:    string(l.Element),
+			Depth:      int32(l.Depth),
+		}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListOutboundLinks returns the links found on the page at url.
+func (r *Repository) ListOutboundLinks(ctx context.Context, jobID string, url string) ([]*model.LinkEdge, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.ListOutboundLinks(ctx, db.ListOutboundLinksParams{JobID: jid, SourceUrl: url})
+	if err != nil {
+		return nil, err
+	}
+	return linksFromDB(rows), nil
+}
+
+// ListInboundLinks returns the links pointing at url from pages of the job.
+func (r *Repository) ListInboundLinks(ctx context.Context, jobID string, url string) ([]*model.LinkEdge, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.ListInboundLinks(ctx, db.ListInboundLinksParams{JobID: jid, TargetUrl: url})
+	if err != nil {
+		return nil, err
+	}
+	return linksFromDB(rows), nil
+}
+
+// CrawlTree returns every URL in the job's frontier, shallowest first, with the page it
+// was discovered from.
+func (r *Repository) CrawlTree(ctx context.Context, jobID string) ([]*model.CrawlTreeNode, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.GetCrawlTree(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.CrawlTreeNode, len(rows))
+	for i := range rows {
+		out[i] = &model.CrawlTreeNode{
+			URL:            rows[i].Url,
+			Depth:          int(rows[i].Depth),
+			DiscoveredFrom: rows[i].DiscoveredFrom.String,
+			State:          model.FrontierState(rows[i].State),
+			AnchorText:     rows[i].AnchorText.String,
+		}
+	}
+	return out, nil
+}
+
+// CrawlPath returns the chain of discoveries that led the job to url, from the start
+// URL down to url itself. It is empty if url is not in the job's frontier.
+func (r *Repository) CrawlPath(ctx context.Context, jobID string, url string) ([]*model.CrawlTreeNode, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.GetCrawlPath(ctx, db.GetCrawlPathParams{JobID: jid, Url: url})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.CrawlTreeNode, len(rows))
+	for i := range rows {
+		out[i] = &model.CrawlTreeNode{
+			URL:            rows[i].Url,
+			Depth:          int(rows[i].Depth),
+			DiscoveredFrom: rows[i].DiscoveredFrom.String,
+			State:          model.FrontierState(rows[i].State),
+			AnchorText:     rows[i].AnchorText.String,
+		}
+	}
+	return out, nil
+}
+
+func linksFromDB(rows []db.Link) []*model.LinkEdge {
+	out := make([]*model.LinkEdge, len(rows))
+	for i := range rows {
+		out[i] = &model.LinkEdge{
+			ID:           int(rows[i].ID),
+			JobID:        uuid.UUID(rows[i].JobID.Bytes).String(),
+			SourceURL:    rows[i].SourceUrl,
+			TargetURL:    rows[i].TargetUrl,
+			AnchorText:   rows[i].AnchorText,
+			Rel:          strings.Fields(rows[i].Rel),
+			Element:      model.LinkSource(rows[i].Element),
+			Depth:        int(rows[i].Depth),
+			DiscoveredAt: rows[i].DiscoveredAt.Time,
+		}
+	}
+	return out
+}
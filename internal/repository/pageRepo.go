@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 
 	"go-crawler/internal/db"
 	"go-crawler/internal/model"
@@ -20,17 +21,66 @@ func (r *Repository) UpsertPage(ctx context.Context, page *model.Page) (*model.P
 	if err != nil {
 		return nil, err
 	}
+	statsJSON, err := json.Marshal(page.Stats)
+	if err != nil {
+		return nil, err
+	}
+	var headersJSON []byte
+	if page.Headers != nil {
+		if headersJSON, err = json.Marshal(page.Headers); err != nil {
+			return nil, err
+		}
+	}
+	var redirectsJSON []byte
+	if len(page.RedirectChain) > 0 {
+		if redirectsJSON, err = json.Marshal(page.RedirectChain); err != nil {
+			return nil, err
+		}
+	}
+	metadataJSON, err := json.Marshal(page.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	var extractedJSON []byte
+	if page.Extracted != nil {
+		if extractedJSON, err = json.Marshal(page.Extracted); err != nil {
+			return nil, err
+		}
+	}
+	var tablesJSON []byte
+	if len(page.Tables) > 0 {
+		if tablesJSON, err = json.Marshal(page.Tables); err != nil {
+			return nil, err
+		}
+	}
+	// page.URL is stored as is: the engine normalized it with the job's normalizer, so it
+	// matches the URL's key in the frontier.
 	row, err := r.queries.UpsertPage(ctx, db.UpsertPageParams{
-		JobID:       jobID,
-		Url:         page.URL,
-		Title:       pgtype.Text{String: page.Title, Valid: page.Title != ""},
-		Html:        page.Html,
-		TextContent: page.TextContent,
+		JobID:           jobID,
+		Url:             page.URL,
+		Title:           pgtype.Text{String: page.Title, Valid: page.Title != ""},
+		Html:            page.Html,
+		TextContent:     page.TextContent,
+		MainContent:     page.MainContent,
+		Stats:           statsJSON,
+		StatusCode:      pgtype.Int4{Int32: int32(page.StatusCode), Valid: page.StatusCode != 0},
+		FinalUrl:        pgtype.Text{String: page.FinalURL, Valid: page.FinalURL != ""},
+		ContentType:     pgtype.Text{String: page.ContentType, Valid: page.ContentType != ""},
+		Charset:         pgtype.Text{String: page.Charset, Valid: page.Charset != ""},
+		Headers:         headersJSON,
+		ContentLength:   pgtype.Int8{Int64: page.ContentLength, Valid: page.StatusCode != 0},
+		FetchDurationMs: pgtype.Int4{Int32: int32(page.FetchDurationMs), Valid: page.StatusCode != 0},
+		ContentHash:     pgtype.Text{String: page.ContentHash, Valid: page.ContentHash != ""},
+		RedirectChain:   redirectsJSON,
+		CanonicalUrl:    pgtype.Text{String: page.CanonicalURL, Valid: page.CanonicalURL != ""},
+		Metadata:        metadataJSON,
+		Extracted:       extractedJSON,
+		Tables:          tablesJSON,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return pageFromDB(&row), nil
+	return pageFromDB(&row)
 }
 
 func (r *Repository) GetPagesByJobID(ctx context.Context, jobID string) ([]*model.Page, error) {
@@ -44,7 +94,55 @@ func (r *Repository) GetPagesByJobID(ctx context.Context, jobID string) ([]*mode
 	}
 	out := make([]*model.Page, len(rows))
 	for i := range rows {
-		out[i] = pageFromDB(&rows[i])
+		p, err := pageFromDB(&rows[i])
+		if err != nil {
+			return nil, err
+		}
+		out[i] = p
+	}
+	return out, nil
+}
+
+// ListExtracted returns the extraction results of a job's pages, in page ID order.
+// Pages stored without extraction rules are left out.
+func (r *Repository) ListExtracted(ctx context.Context, jobID string) ([]*model.ExtractedRecord, error) {
+	uid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.ListExtractedByJobID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.ExtractedRecord, len(rows))
+	for i := range rows {
+		rec := &model.ExtractedRecord{PageID: int(rows[i].ID), URL: rows[i].Url}
+		if err := json.Unmarshal(rows[i].Extracted, &rec.Data); err != nil {
+			return nil, err
+		}
+		out[i] = rec
+	}
+	return out, nil
+}
+
+// ListTables returns the tables of a job's pages, in page ID order. Pages without
+// tables are left out.
+func (r *Repository) ListTables(ctx context.Context, jobID string) ([]*model.PageTables, error) {
+	uid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.ListTablesByJobID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.PageTables, len(rows))
+	for i := range rows {
+		pt := &model.PageTables{PageID: int(rows[i].ID), URL: rows[i].Url}
+		if err := json.Unmarshal(rows[i].Tables, &pt.Tables); err != nil {
+			return nil, err
+		}
+		out[i] = pt
 	}
 	return out, nil
 }
@@ -54,19 +152,58 @@ func (r *Repository) CreatePage(ctx context.Context, page *model.Page) error {
 	return err
 }
 
-func pageFromDB(row *db.Page) *model.Page {
+func pageFromDB(row *db.Page) (*model.Page, error) {
 	p := &model.Page{
-		ID:          int(row.ID),
-		JobID:       uuid.UUID(row.JobID.Bytes).String(),
-		URL:         row.Url,
-		Html:        row.Html,
-		TextContent: row.TextContent,
-		FetchedAt:   row.FetchedAt.Time,
+		ID:              int(row.ID),
+		JobID:           uuid.UUID(row.JobID.Bytes).String(),
+		URL:             row.Url,
+		Html:            row.Html,
+		TextContent:     row.TextContent,
+		MainContent:     row.MainContent,
+		FetchedAt:       row.FetchedAt.Time,
+		StatusCode:      int(row.StatusCode.Int32),
+		FinalURL:        row.FinalUrl.String,
+		ContentType:     row.ContentType.String,
+		Charset:         row.Charset.String,
+		ContentLength:   row.ContentLength.Int64,
+		FetchDurationMs: int(row.FetchDurationMs.Int32),
+		ContentHash:     row.ContentHash.String,
+		CanonicalURL:    row.CanonicalUrl.String,
 	}
 	if row.Title.Valid {
 		p.Title = row.Title.String
 	}
-	return p
+	if len(row.Stats) > 0 {
+		if err := json.Unmarshal(row.Stats, &p.Stats); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.Headers) > 0 {
+		if err := json.Unmarshal(row.Headers, &p.Headers); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.RedirectChain) > 0 {
+		if err := json.Unmarshal(row.RedirectChain, &p.RedirectChain); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.Metadata) > 0 {
+		if err := json.Unmarshal(row.Metadata, &p.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.Extracted) > 0 {
+		if err := json.Unmarshal(row.Extracted, &p.Extracted); err != nil {
+			return nil, err
+		}
+	}
+	if len(row.Tables) > 0 {
+		if err := json.Unmarshal(row.Tables, &p.Tables); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
 }
 
 func uuidFromString(s string) (pgtype.UUID, error) {
@@ -75,17 +212,21 @@ func uuidFromString(s string) (pgtype.UUID, error) {
 	return u, err
 }
 
-func (r *Repository) ListPagesForIndex(ctx context.Context) ([]search.Document, error) {
+// ListPagesForIndex returns every stored page as a search document, with main content
+// terms counting mainContentWeight times as IndexingWriterImpl indexes them.
+func (r *Repository) ListPagesForIndex(ctx context.Context, mainContentWeight float64) ([]search.Document, error) {
 	rows, err := r.queries.ListPagesForIndex(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return indexDocuments(rows, mainContentWeight), nil
+}
+
+func indexDocuments(rows []db.ListPagesForIndexRow, mainContentWeight float64) []search.Document {
 	out := make([]search.Document, len(rows))
 	for i := range rows {
-		out[i] = search.Document{
-			ID:   int(rows[i].ID),
-			Text: rows[i].Title.String + " " + rows[i].TextContent,
-		}
+		row := &rows[i]
+		out[i] = search.PageDocument(int(row.ID), row.Url, row.CanonicalUrl.String, row.Title.String, row.TextContent, row.MainContent, mainContentWeight)
 	}
-	return out, nil
+	return out
 }
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"go-crawler/internal/db"
+	"go-crawler/internal/search"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestIndexDocumentsMainContentWeight(t *testing.T) {
+	rows := []db.ListPagesForIndexRow{{
+		ID:           3,
+		Title:        pgtype.Text{String: "Title", Valid: true},
+		TextContent:  "nav article footer",
+		MainContent:  "article",
+		Url:          "https://example.com/a?x=1",
+		CanonicalUrl: pgtype.Text{String: "https://example.com/a", Valid: true},
+	}}
+	for _, weight := range []float64{1, search.DefaultMainContentWeight, 5} {
+		got := indexDocuments(rows, weight)
+		want := []search.Document{search.PageDocument(3, "https://example.com/a?x=1", "https://example.com/a",
+			"Title", "nav article footer", "article", weight)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("weight %v: got %+v, want %+v", weight, got, want)
+		}
+	}
+}
@@ -35,7 +35,7 @@ func RunSchema(ctx context.Context, pool *pgxpool.Pool, schema string) error {
 	return err
 }
 
-// schemaSQL is the initial schema; must match internal/sql/schema/001_schema.sql.
+// schemaSQL is the full schema; must match the files in internal/sql/schema, in order.
 const schemaSQL = `CREATE TABLE IF NOT EXISTS jobs (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     input JSONB NOT NULL,
@@ -54,7 +54,97 @@ CREATE TABLE IF NOT EXISTS pages (
     html TEXT NOT NULL,
     text_content TEXT NOT NULL,
     fetched_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-);`
+);
+
+CREATE TABLE IF NOT EXISTS skipped_urls (
+    id SERIAL PRIMARY KEY,
+    job_id UUID REFERENCES jobs(id),
+    url TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    skipped_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS skipped_urls_job_id_idx ON skipped_urls (job_id);
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS stats JSONB;
+
+CREATE TABLE IF NOT EXISTS frontier (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id),
+    url TEXT NOT NULL,
+    depth INT NOT NULL,
+    state TEXT NOT NULL,
+    discovered_from TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (job_id, url)
+);
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS owner_instance TEXT;
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS jobs_pending_queue_idx ON jobs (priority DESC, created_at, id) WHERE status = 'PENDING';
+
+ALTER TABLE frontier ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE frontier ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS fetches (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id),
+    url TEXT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT NOT NULL,
+    latency_ms INT NOT NULL,
+    bytes BIGINT NOT NULL,
+    content_type TEXT NOT NULL,
+    error_class TEXT NOT NULL,
+    error TEXT NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS fetches_job_id_idx ON fetches (job_id, id);
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS status_code INT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS final_url TEXT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS content_type TEXT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS charset TEXT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS headers JSONB;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS content_length BIGINT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS fetch_duration_ms INT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS content_hash TEXT;
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS redirect_chain JSONB;
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS main_content TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+
+CREATE INDEX IF NOT EXISTS pages_canonical_url_idx ON pages (canonical_url);
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS metadata JSONB;
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS extracted JSONB;
+
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS tables JSONB;
+
+CREATE TABLE IF NOT EXISTS links (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id),
+    source_url TEXT NOT NULL,
+    target_url TEXT NOT NULL,
+    anchor_text TEXT NOT NULL,
+    rel TEXT NOT NULL,
+    element TEXT NOT NULL,
+    depth INT NOT NULL,
+    discovered_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (job_id, source_url, target_url)
+);
+
+CREATE INDEX IF NOT EXISTS links_target_idx ON links (job_id, target_url);
+
+ALTER TABLE fetches ADD COLUMN IF NOT EXISTS method TEXT NOT NULL DEFAULT 'GET';`
 
 func (r *Repository) Queries(ctx context.Context) *db.Queries {
 	return r.queries
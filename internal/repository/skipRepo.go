@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"go-crawler/internal/db"
+	"go-crawler/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func (r *Repository) RecordSkip(ctx context.Context, jobID string, url string, reason model.SkipReason) error {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return err
+	}
+	return r.queries.CreateSkippedURL(ctx, db.CreateSkippedURLParams{
+		JobID:  jid,
+		Url:    url,
+		Reason: string(reason),
+	})
+}
+
+func (r *Repository) GetSkippedURLsByJobID(ctx context.Context, jobID string) ([]*model.SkippedURL, error) {
+	jid, err := uuidFromString(jobID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.queries.GetSkippedURLsByJobID(ctx, jid)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.SkippedURL, len(rows))
+	for i := range rows {
+		out[i] = &model.SkippedURL{
+			ID:        int(rows[i].ID),
+			JobID:     uuid.UUID(rows[i].JobID.Bytes).String(),
+			URL:       rows[i].Url,
+			Reason:    model.SkipReason(rows[i].Reason),
+			SkippedAt: rows[i].SkippedAt.Time,
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,208 @@
+package robots
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// maxRobotsSize caps how much of a robots.txt is read; the rest is ignored.
+const maxRobotsSize = 500 * 1024
+
+// cacheTTL is how long a fetched robots.txt is reused before fetching it again.
+const cacheTTL = time.Hour
+
+// unreachableTTL is how long a failed robots.txt fetch blocks an origin before retrying.
+const unreachableTTL = time.Minute
+
+// Checker fetches and caches robots.txt per origin (scheme + host) and answers
+// whether a URL may be crawled by userAgent. Safe for concurrent use.
+type Checker struct {
+	client    *http.Client
+	userAgent string
+	// OnFetch, if set, is called after every robots.txt request with the context of
+	// the caller that triggered it. Set it before the Checker is used.
+	OnFetch func(ctx context.Context, f FetchInfo)
+
+	mu      sync.Mutex
+	origins map[string]*originEntry
+}
+
+// FetchInfo describes one robots.txt request. StatusCode is 0 when no response was
+// received; Err is set when the request or the body read failed.
+type FetchInfo struct {
+	URL         string
+	StatusCode  int
+	Latency     time.Duration
+	Bytes       int
+	ContentType string
+	Err         error
+}
+
+// originEntry is one cached robots.txt. ready is closed once the fetch ends, so
+// concurrent callers for the same origin wait for a single fetch. group stays nil
+// if the fetch was cancelled.
+type originEntry struct {
+	ready     chan struct{}
+	group     *Group
+	expiresAt time.Time
+}
+
+// NewChecker returns a Checker that fetches with client and matches groups for userAgent.
+func NewChecker(client *http.Client, userAgent string) *Checker {
+	return &Checker{
+		client:    client,
+		userAgent: userAgent,
+		origins:   make(map[string]*originEntry),
+	}
+}
+
+// Allowed reports whether rawURL may be fetched according to its origin's robots.txt,
+// fetching and caching the file on first use.
+func (c *Checker) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+	group, err := c.group(ctx, u)
+	if err != nil {
+		return false, err
+	}
+	return group.Allowed(u.EscapedPath() + queryPart(u)), nil
+}
+
+// CachedCrawlDelay returns the Crawl-delay for rawURL's origin if its robots.txt has already
+// been fetched, without fetching it. It returns 0 when unknown or unset.
+func (c *Checker) CachedCrawlDelay(rawURL string) time.Duration {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	c.mu.Lock()
+	entry, ok := c.origins[u.Scheme+"://"+u.Host]
+	c.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	select {
+	case <-entry.ready:
+		if entry.group == nil {
+			return 0
+		}
+		return entry.group.CrawlDelay
+	default:
+		return 0
+	}
+}
+
+func queryPart(u *url.URL) string {
+	if u.RawQuery == "" {
+		return ""
+	}
+	return "?" + u.RawQuery
+}
+
+func (c *Checker) group(ctx context.Context, u *url.URL) (*Group, error) {
+	origin := u.Scheme + "://" + u.Host
+
+	for {
+		c.mu.Lock()
+		entry, ok := c.origins[origin]
+		if ok && entryExpired(entry) {
+			ok = false
+		}
+		if !ok {
+			entry = &originEntry{ready: make(chan struct{})}
+			c.origins[origin] = entry
+			c.mu.Unlock()
+			return c.load(ctx, origin, entry)
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-entry.ready:
+			if entry.group != nil {
+				return entry.group, nil
+			}
+			// The fetching caller was cancelled; try again with our own context.
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// load fetches robots.txt into entry and wakes up waiters. A fetch aborted by ctx is
+// not cached, so one cancelled job cannot block an origin for everyone else.
+func (c *Checker) load(ctx context.Context, origin string, entry *originEntry) (*Group, error) {
+	defer close(entry.ready)
+
+	group, ttl := c.fetch(ctx, origin)
+	if ctx.Err() != nil {
+		c.mu.Lock()
+		if c.origins[origin] == entry {
+			delete(c.origins, origin)
+		}
+		c.mu.Unlock()
+		return nil, ctx.Err()
+	}
+	entry.group = group
+	entry.expiresAt = time.Now().Add(ttl)
+	return group, nil
+}
+
+// entryExpired reports whether a finished entry has outlived its TTL.
+// Entries still being fetched are never expired. Must be called with c.mu held.
+func entryExpired(entry *originEntry) bool {
+	select {
+	case <-entry.ready:
+		return time.Now().After(entry.expiresAt)
+	default:
+		return false
+	}
+}
+
+// fetch downloads robots.txt for origin and returns the group for our agent and how long
+// to cache it. Following RFC 9309, a 4xx response means no restrictions, while a 5xx or
+// network error means the whole site is disallowed (cached briefly, as it may recover).
+func (c *Checker) fetch(ctx context.Context, origin string) (*Group, time.Duration) {
+	req, err := http.NewRequestWithContext(ctx, "GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return disallowAll, unreachableTTL
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	info := FetchInfo{URL: req.URL.String()}
+	start := time.Now()
+	if c.OnFetch != nil {
+		defer func() {
+			info.Latency = time.Since(start)
+			c.OnFetch(ctx, info)
+		}()
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		info.Err = err
+		return disallowAll, unreachableTTL
+	}
+	defer resp.Body.Close()
+	info.StatusCode = resp.StatusCode
+	info.ContentType = resp.Header.Get("Content-Type")
+
+	switch {
+	case resp.StatusCode >= 500:
+		return disallowAll, unreachableTTL
+	case resp.StatusCode != http.StatusOK:
+		return allowAll, cacheTTL
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+	info.Bytes = len(body)
+	if err != nil {
+		info.Err = err
+		return disallowAll, unreachableTTL
+	}
+	return Parse(body).Group(c.userAgent), cacheTTL
+}
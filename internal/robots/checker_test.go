@@ -0,0 +1,186 @@
+package robots
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// robotsServer serves robots.txt through handler and counts the requests for it.
+func robotsServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		handler(w, r)
+	})
+	mux.HandleFunc("/moved/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\nCrawl-delay: 2\n")
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestCheckerResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+		want    bool
+	}{
+		{"rules", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "User-agent: go-crawler\nDisallow: /private\n")
+		}, "/private/x", false},
+		{"allowed path", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "User-agent: go-crawler\nDisallow: /private\n")
+		}, "/public", true},
+		{"not found allows all", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		}, "/private", true},
+		{"server error disallows all", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, "/public", false},
+		{"redirect is followed", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/moved/robots.txt", http.StatusMovedPermanently)
+		}, "/private", false},
+		{"redirect target rules only", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/moved/robots.txt", http.StatusFound)
+		}, "/public", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := robotsServer(t, tt.handler)
+			c := NewChecker(srv.Client(), "go-crawler")
+			got, err := c.Allowed(context.Background(), srv.URL+tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Allowed(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckerRedirectCrawlDelay(t *testing.T) {
+	srv, _ := robotsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/moved/robots.txt", http.StatusMovedPermanently)
+	})
+	c := NewChecker(srv.Client(), "go-crawler")
+	if d := c.CachedCrawlDelay(srv.URL + "/"); d != 0 {
+		t.Errorf("CachedCrawlDelay before fetch = %v, want 0", d)
+	}
+	if _, err := c.Allowed(context.Background(), srv.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+	if d := c.CachedCrawlDelay(srv.URL + "/"); d != 2*time.Second {
+		t.Errorf("CachedCrawlDelay = %v, want 2s", d)
+	}
+}
+
+func TestCheckerSingleFetch(t *testing.T) {
+	release := make(chan struct{})
+	srv, hits := robotsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	c := NewChecker(srv.Client(), "go-crawler")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if allowed, err := c.Allowed(context.Background(), srv.URL+"/private"); err != nil || allowed {
+				errs <- fmt.Errorf("Allowed = %v, %v; want false, nil", allowed, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", n)
+	}
+}
+
+func TestCheckerExpiry(t *testing.T) {
+	srv, hits := robotsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	c := NewChecker(srv.Client(), "go-crawler")
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := c.Allowed(ctx, srv.URL+"/"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("robots.txt fetched %d times before expiry, want 1", n)
+	}
+
+	c.mu.Lock()
+	c.origins[srv.URL].expiresAt = time.Now().Add(-time.Second)
+	c.mu.Unlock()
+	if _, err := c.Allowed(ctx, srv.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("robots.txt fetched %d times after expiry, want 2", n)
+	}
+}
+
+func TestCheckerUnreachableTTL(t *testing.T) {
+	srv, _ := robotsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	c := NewChecker(srv.Client(), "go-crawler")
+	if _, err := c.Allowed(context.Background(), srv.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	ttl := time.Until(c.origins[srv.URL].expiresAt)
+	c.mu.Unlock()
+	if ttl <= 0 || ttl > unreachableTTL {
+		t.Errorf("server error cached for %v, want at most %v", ttl, unreachableTTL)
+	}
+}
+
+func TestCheckerCancelledFetchNotCached(t *testing.T) {
+	srv, hits := robotsServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	c := NewChecker(srv.Client(), "go-crawler")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Allowed(ctx, srv.URL+"/"); err == nil {
+		t.Fatal("Allowed with a cancelled context succeeded")
+	}
+	c.mu.Lock()
+	_, cached := c.origins[srv.URL]
+	c.mu.Unlock()
+	if cached {
+		t.Fatal("cancelled fetch was cached")
+	}
+
+	allowed, err := c.Allowed(context.Background(), srv.URL+"/private")
+	if err != nil || allowed {
+		t.Errorf("Allowed = %v, %v; want false, nil", allowed, err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("robots.txt fetched %d times, want 1", n)
+	}
+}
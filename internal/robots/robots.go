@@ -0,0 +1,187 @@
+package robots
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rules is a parsed robots.txt file: a list of user-agent groups.
+type Rules struct {
+	groups []*Group
+}
+
+// Group holds the Allow/Disallow rules and Crawl-delay that apply to a set of user agents.
+type Group struct {
+	agents     []string
+	rules      []rule
+	CrawlDelay time.Duration
+}
+
+type rule struct {
+	allow   bool
+	pattern string
+}
+
+// allowAll is used when robots.txt is missing or has no group for our agent.
+var allowAll = &Group{}
+
+// disallowAll is used when robots.txt is unreachable (5xx or network error).
+var disallowAll = &Group{rules: []rule{{allow: false, pattern: "/"}}}
+
+// Parse reads a robots.txt body. Unknown directives and malformed lines are ignored.
+// Consecutive User-agent lines share the rules that follow them.
+func Parse(body []byte) *Rules {
+	rules := &Rules{}
+	var current *Group
+	inAgents := false // true while reading a run of User-agent lines
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				current = &Group{}
+				rules.groups = append(rules.groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			// An empty Disallow means "allow everything" and adds no rule.
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, rule{allow: key == "allow", pattern: value})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			secs, err := strconv.ParseFloat(value, 64)
+			if err != nil || secs < 0 {
+				continue
+			}
+			current.CrawlDelay = time.Duration(secs * float64(time.Second))
+		default:
+			// Sitemap and unknown directives do not end a group's User-agent run
+			// but are otherwise ignored.
+		}
+	}
+	return rules
+}
+
+// Group returns the group for userAgent: the groups whose User-agent names exactly that
+// product token (case-insensitively, ignoring any "/version"), else the "*" group, else a
+// group that allows everything. Following RFC 9309, a token is not matched by prefix, so
+// "User-agent: go" does not apply to "go-crawler". Groups naming the same agent are merged.
+func (r *Rules) Group(userAgent string) *Group {
+	ua := productToken(userAgent)
+	var matched, wildcard []*Group
+	for _, g := range r.groups {
+		switch {
+		case slices.ContainsFunc(g.agents, func(a string) bool { return productToken(a) == ua }):
+			matched = append(matched, g)
+		case slices.Contains(g.agents, "*"):
+			wildcard = append(wildcard, g)
+		}
+	}
+	switch {
+	case len(matched) > 0:
+		return mergeGroups(matched)
+	case len(wildcard) > 0:
+		return mergeGroups(wildcard)
+	default:
+		return allowAll
+	}
+}
+
+// productToken lowercases a user agent and drops a trailing "/version".
+func productToken(agent string) string {
+	name, _, _ := strings.Cut(agent, "/")
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func mergeGroups(groups []*Group) *Group {
+	if len(groups) == 1 {
+		return groups[0]
+	}
+	merged := &Group{}
+	for _, g := range groups {
+		merged.agents = append(merged.agents, g.agents...)
+		merged.rules = append(merged.rules, g.rules...)
+		if g.CrawlDelay > merged.CrawlDelay {
+			merged.CrawlDelay = g.CrawlDelay
+		}
+	}
+	return merged
+}
+
+// Allowed reports whether path (path plus optional "?query") may be fetched.
+// The longest matching pattern wins; on a tie Allow wins. No match means allowed.
+func (g *Group) Allowed(path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	allowed := true
+	bestLen := -1
+	for _, r := range g.rules {
+		if !match(r.pattern, path) {
+			continue
+		}
+		n := len(r.pattern)
+		if n > bestLen || (n == bestLen && r.allow) {
+			bestLen = n
+			allowed = r.allow
+		}
+	}
+	return allowed
+}
+
+// match reports whether pattern matches the start of path. "*" matches any run of
+// characters and a trailing "$" anchors the pattern to the end of path.
+func match(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	parts := strings.Split(pattern, "*")
+
+	// The first part must be a prefix; the rest are found left to right.
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i := 1; i < len(parts); i++ {
+		part := parts[i]
+		if i == len(parts)-1 && anchored {
+			return strings.HasSuffix(path[pos:], part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	if anchored {
+		return pos == len(path)
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package robots
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupSelection(t *testing.T) {
+	const body = `
+# comment
+User-agent: *
+Disallow: /all
+
+User-agent: go
+Disallow: /go
+
+User-agent: Go-Crawler
+User-agent: other
+Disallow: /ours
+Crawl-delay: 1.5
+
+User-agent: go-crawler/2.0
+Disallow: /versioned
+`
+	rules := Parse([]byte(body))
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"go-crawler", "/ours", false},
+		{"go-crawler", "/versioned", false}, // same product token, merged
+		{"go-crawler", "/go", true},         // "go" is not a prefix match
+		{"go-crawler", "/all", true},        // a named group replaces "*"
+		{"GO-CRAWLER", "/ours", false},
+		{"other", "/ours", false},
+		{"go", "/go", false},
+		{"go", "/ours", true},
+		{"unknown-bot", "/all", false},
+		{"unknown-bot", "/ours", true},
+	}
+	for _, tt := range tests {
+		if got := rules.Group(tt.agent).Allowed(tt.path); got != tt.want {
+			t.Errorf("Group(%q).Allowed(%q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+	if d := rules.Group("go-crawler").CrawlDelay; d != 1500*time.Millisecond {
+		t.Errorf("CrawlDelay = %v, want 1.5s", d)
+	}
+}
+
+func TestNoMatchingGroup(t *testing.T) {
+	rules := Parse([]byte("User-agent: someone\nDisallow: /\n"))
+	if g := rules.Group("go-crawler"); g != allowAll {
+		t.Errorf("Group = %+v, want allowAll", g)
+	}
+	if g := Parse(nil).Group("go-crawler"); g != allowAll {
+		t.Errorf("empty robots.txt: Group = %+v, want allowAll", g)
+	}
+}
+
+func TestParse(t *testing.T) {
+	const body = `Disallow: /orphan
+user-agent: a
+Sitemap: https://example.com/sitemap.xml
+USER-AGENT: b
+disallow: /x # trailing comment
+Disallow:
+not a directive
+Crawl-delay: soon
+Allow: /x/y
+User-agent: c
+Crawl-delay: -1
+`
+	rules := Parse([]byte(body))
+	if len(rules.groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(rules.groups))
+	}
+	ab := rules.groups[0]
+	if len(ab.agents) != 2 || ab.agents[0] != "a" || ab.agents[1] != "b" {
+		t.Errorf("agents = %v, want [a b]", ab.agents)
+	}
+	want := []rule{{allow: false, pattern: "/x"}, {allow: true, pattern: "/x/y"}}
+	if len(ab.rules) != len(want) {
+		t.Fatalf("rules = %+v, want %+v", ab.rules, want)
+	}
+	for i := range want {
+		if ab.rules[i] != want[i] {
+			t.Errorf("rules[%d] = %+v, want %+v", i, ab.rules[i], want[i])
+		}
+	}
+	if ab.CrawlDelay != 0 {
+		t.Errorf("invalid Crawl-delay parsed as %v", ab.CrawlDelay)
+	}
+	if c := rules.groups[1]; c.CrawlDelay != 0 || len(c.rules) != 0 {
+		t.Errorf("group c = %+v, want no rules and no delay", c)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []rule
+		path  string
+		want  bool
+	}{
+		{"no rules", nil, "/a", true},
+		{"empty path is root", []rule{{false, "/"}}, "", false},
+		{"prefix", []rule{{false, "/a"}}, "/abc", false},
+		{"no match", []rule{{false, "/a"}}, "/b", true},
+		{"longest wins allow", []rule{{false, "/a"}, {true, "/a/b"}}, "/a/b/c", true},
+		{"longest wins disallow", []rule{{true, "/a"}, {false, "/a/b"}}, "/a/b/c", false},
+		{"tie goes to allow", []rule{{false, "/a"}, {true, "/a"}}, "/a", true},
+		{"wildcard", []rule{{false, "/*.php"}}, "/x/y.php?z=1", false},
+		{"wildcard no match", []rule{{false, "/*.php"}}, "/x/y.html", true},
+		{"several wildcards", []rule{{false, "/a*b*c"}}, "/a-x-b-y-c", false},
+		{"wildcards in order", []rule{{false, "/a*b*c"}}, "/a-c-b", true},
+		{"anchored", []rule{{false, "/a$"}}, "/a", false},
+		{"anchored longer path", []rule{{false, "/a$"}}, "/ab", true},
+		{"anchored wildcard", []rule{{false, "/*.gif$"}}, "/img/x.gif", false},
+		{"anchored wildcard suffix", []rule{{false, "/*.gif$"}}, "/img/x.gif?v=1", true},
+		{"query", []rule{{false, "/search?q="}}, "/search?q=go", false},
+		{"longer wildcard allow", []rule{{false, "/"}, {true, "/*.css$"}}, "/s/site.css", true},
+	}
+	for _, tt := range tests {
+		g := &Group{rules: tt.rules}
+		if got := g.Allowed(tt.path); got != tt.want {
+			t.Errorf("%s: Allowed(%q) = %v, want %v", tt.name, tt.path, got, tt.want)
+		}
+	}
+}
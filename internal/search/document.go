@@ -1,7 +1,32 @@
 package search
 
+// DefaultMainContentWeight is how much more a term in a page's main content counts
+// than one elsewhere on the page.
+const DefaultMainContentWeight = 2.0
+
 // Document is input for building the index: one page with ID and text to tokenize.
+// Fields are indexed in addition to Text, each term counting Weight times. The index
+// holds one document per CanonicalURL (or URL, if none was declared).
 type Document struct {
-	ID   int
-	Text string
+	ID           int
+	URL          string
+	CanonicalURL string
+	Text         string
+	Fields       []Field
+}
+
+// Field is text indexed with a weight other than 1.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// PageDocument builds the document for a page. mainContent is part of text, so it is
+// added again with the remaining weight for its terms to count mainWeight times in all.
+func PageDocument(id int, url, canonicalURL, title, text, mainContent string, mainWeight float64) Document {
+	doc := Document{ID: id, URL: url, CanonicalURL: canonicalURL, Text: title + " " + text}
+	if mainContent != "" && mainWeight > 1 {
+		doc.Fields = []Field{{Text: mainContent, Weight: mainWeight - 1}}
+	}
+	return doc
 }
@@ -10,15 +10,27 @@ import (
 
 type Index struct {
 	mu        sync.RWMutex
-	entries   map[string]map[int]int // term -> document ID -> count
-	docLen    map[int]int            // document ID -> total token count
-	totalDocs int                    // number of documents indexed
+	entries   map[string]map[int]float64 // term -> document ID -> weighted count
+	docLen    map[int]float64            // document ID -> weighted token count
+	totalDocs int                        // number of documents indexed
+	// Pages sharing a canonical URL are indexed as one document.
+	byKey map[string]int       // canonical URL -> ID of the document holding it
+	docs  map[int]docCanonical // document ID -> its canonical URL
+}
+
+// docCanonical is the canonical URL a document is indexed under, and whether the
+// document is the canonical page itself rather than a duplicate of it.
+type docCanonical struct {
+	key       string
+	canonical bool
 }
 
 func NewIndex() *Index {
 	return &Index{
-		entries: make(map[string]map[int]int),
-		docLen:  make(map[int]int),
+		entries: make(map[string]map[int]float64),
+		docLen:  make(map[int]float64),
+		byKey:   make(map[string]int),
+		docs:    make(map[int]docCanonical),
 	}
 }
 
@@ -40,18 +52,72 @@ func (i *Index) BuildFromDocuments(documents []Document) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
 
-	i.entries = make(map[string]map[int]int)
-	i.docLen = make(map[int]int)
-	i.totalDocs = len(documents)
+	i.entries = make(map[string]map[int]float64)
+	i.docLen = make(map[int]float64)
+	i.byKey = make(map[string]int)
+	i.docs = make(map[int]docCanonical)
 
 	for _, doc := range documents {
-		terms := i.Tokenize(doc.Text)
-		i.docLen[doc.ID] = len(terms)
-		for _, term := range terms {
+		i.add(doc)
+	}
+	i.totalDocs = len(i.docLen)
+}
+
+// add indexes doc, replacing any earlier version of it. A document whose canonical
+// URL is already held by another document replaces that one, unless that one is the
+// canonical page itself and doc only a duplicate. The caller holds the write lock.
+func (i *Index) add(doc Document) {
+	i.remove(doc.ID)
+	c := docCanonical{key: doc.CanonicalURL, canonical: doc.CanonicalURL == "" || doc.CanonicalURL == doc.URL}
+	if c.key == "" {
+		c.key = doc.URL
+	}
+	if c.key != "" {
+		if other, ok := i.byKey[c.key]; ok {
+			if i.docs[other].canonical && !c.canonical {
+				return
+			}
+			i.remove(other)
+		}
+		i.byKey[c.key] = doc.ID
+	}
+	i.docs[doc.ID] = c
+	i.index(doc)
+}
+
+// remove drops document id from the index, if present. The caller holds the write lock.
+func (i *Index) remove(id int) {
+	if _, ok := i.docLen[id]; !ok {
+		return
+	}
+	delete(i.docLen, id)
+	for _, postings := range i.entries {
+		delete(postings, id)
+	}
+	if c, ok := i.docs[id]; ok {
+		if i.byKey[c.key] == id {
+			delete(i.byKey, c.key)
+		}
+		delete(i.docs, id)
+	}
+}
+
+// index adds doc's terms to the postings; the caller holds the write lock.
+func (i *Index) index(doc Document) {
+	add := func(text string, weight float64) {
+		for _, term := range i.Tokenize(text) {
 			if _, ok := i.entries[term]; !ok {
-				i.entries[term] = make(map[int]int)
+				i.entries[term] = make(map[int]float64)
 			}
-			i.entries[term][doc.ID]++
+			i.entries[term][doc.ID] += weight
+			i.docLen[doc.ID] += weight
+		}
+	}
+	i.docLen[doc.ID] = 0
+	add(doc.Text, 1)
+	for _, f := range doc.Fields {
+		if f.Weight > 0 {
+			add(f.Text, f.Weight)
 		}
 	}
 }
@@ -86,7 +152,7 @@ func (i *Index) Search(query string) []SearchResult {
 			if dl == 0 {
 				continue
 			}
-			tf := float64(count) / float64(dl)
+			tf := count / dl
 			scores[docID] += tf * idf
 		}
 	}
@@ -110,23 +176,6 @@ func (i *Index) Search(query string) []SearchResult {
 func (i *Index) AddDocument(document Document) {
 	i.mu.Lock()
 	defer i.mu.Unlock()
-	_, isReplace := i.docLen[document.ID]
-	if isReplace {
-		delete(i.docLen, document.ID)
-		for _, postings := range i.entries {
-			delete(postings, document.ID)
-		}
-	}
-	if !isReplace {
-		i.totalDocs++
-	}
-	terms := i.Tokenize(document.Text)
-	i.docLen[document.ID] = len(terms)
-	for _, term := range terms {
-		if _, ok := i.entries[term]; !ok {
-			i.entries[term] = make(map[int]int)
-		}
-		i.entries[term][document.ID]++
-	}
-
+	i.add(document)
+	i.totalDocs = len(i.docLen)
 }
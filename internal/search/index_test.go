@@ -0,0 +1,122 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+// hits returns the IDs of the documents matching query, in ascending order.
+func hits(idx *Index, query string) []int {
+	var ids []int
+	for _, r := range idx.Search(query) {
+		ids = append(ids, r.DocumentID)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func doc(id int, url, canonical, text string) Document {
+	return Document{ID: id, URL: url, CanonicalURL: canonical, Text: text}
+}
+
+func TestCanonicalCollapse(t *testing.T) {
+	const canon = "https://example.com/a"
+	tests := []struct {
+		name string
+		docs []Document
+		want []int
+	}{
+		{
+			name: "duplicates only: the last one seen wins",
+			docs: []Document{doc(1, canon+"?x=1", canon, "shared"), doc(2, canon+"?x=2", canon, "shared")},
+			want: []int{2},
+		},
+		{
+			name: "canonical page replaces a duplicate seen first",
+			docs: []Document{doc(1, canon+"?x=1", canon, "shared"), doc(2, canon, canon, "shared")},
+			want: []int{2},
+		},
+		{
+			name: "canonical page is kept over a later duplicate",
+			docs: []Document{doc(1, canon, canon, "shared"), doc(2, canon+"?x=1", canon, "shared")},
+			want: []int{1},
+		},
+		{
+			name: "page without a canonical is its own canonical",
+			docs: []Document{doc(1, canon, "", "shared"), doc(2, canon+"?x=1", canon, "shared")},
+			want: []int{1},
+		},
+		{
+			name: "different canonicals are separate documents",
+			docs: []Document{doc(1, canon, canon, "shared"), doc(2, canon+"?x=1", "https://example.com/b", "shared")},
+			want: []int{1, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incremental := NewIndex()
+			for _, d := range tt.docs {
+				incremental.AddDocument(d)
+			}
+			built := NewIndex()
+			built.BuildFromDocuments(tt.docs)
+			for name, idx := range map[string]*Index{"AddDocument": incremental, "BuildFromDocuments": built} {
+				if got := hits(idx, "shared"); !slices.Equal(got, tt.want) {
+					t.Errorf("%s: hits = %v, want %v", name, got, tt.want)
+				}
+				if idx.totalDocs != len(tt.want) {
+					t.Errorf("%s: totalDocs = %d, want %d", name, idx.totalDocs, len(tt.want))
+				}
+			}
+		})
+	}
+}
+
+// TestCanonicalChanged re-adds a page after its canonical URL changed: it leaves its
+// old group, which a later duplicate can then take.
+func TestCanonicalChanged(t *testing.T) {
+	idx := NewIndex()
+	idx.AddDocument(doc(1, "https://example.com/a", "https://example.com/a", "old words"))
+	idx.AddDocument(doc(1, "https://example.com/a", "https://example.com/b", "new words"))
+	if got := hits(idx, "old"); got != nil {
+		t.Errorf("old text still matches %v", got)
+	}
+	if got := hits(idx, "new"); !slices.Equal(got, []int{1}) {
+		t.Errorf("hits(new) = %v, want [1]", got)
+	}
+
+	// /a is free again, and /b is held by a duplicate, so a page canonical to /b
+	// replaces it.
+	idx.AddDocument(doc(2, "https://example.com/a?x=1", "https://example.com/a", "words"))
+	idx.AddDocument(doc(3, "https://example.com/b", "https://example.com/b", "words"))
+	if got := hits(idx, "words"); !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("hits(words) = %v, want [2 3]", got)
+	}
+	if idx.totalDocs != 2 {
+		t.Errorf("totalDocs = %d, want 2", idx.totalDocs)
+	}
+}
+
+// TestSurvivorRemoved moves the document holding a canonical URL out of its group: the
+// URL is released, so the next page declaring it is indexed rather than dropped.
+func TestSurvivorRemoved(t *testing.T) {
+	const canon = "https://example.com/a"
+	idx := NewIndex()
+	idx.AddDocument(doc(1, canon, canon, "shared"))
+	idx.AddDocument(doc(2, canon+"?x=1", canon, "shared")) // dropped: 1 is the canonical page
+	if got := hits(idx, "shared"); !slices.Equal(got, []int{1}) {
+		t.Fatalf("hits = %v, want [1]", got)
+	}
+
+	idx.AddDocument(doc(1, "https://example.com/c", "", "moved"))
+	if got := hits(idx, "shared"); got != nil {
+		t.Errorf("after the survivor left: hits = %v, want none", got)
+	}
+	idx.AddDocument(doc(2, canon+"?x=1", canon, "shared"))
+	if got := hits(idx, "shared"); !slices.Equal(got, []int{2}) {
+		t.Errorf("after re-adding the duplicate: hits = %v, want [2]", got)
+	}
+	if idx.totalDocs != 2 {
+		t.Errorf("totalDocs = %d, want 2", idx.totalDocs)
+	}
+}
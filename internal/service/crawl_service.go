@@ -2,18 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-crawler/internal/model"
+	"log"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrJobNotFound is returned by the JobRepository when no job has the given ID.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrInvalidInput is returned by Submit when the runner rejects the job's input.
+var ErrInvalidInput = errors.New("invalid crawl input")
+
+// ErrJobNotActive is returned by Cancel when the job has already finished.
+var ErrJobNotActive = errors.New("job is not pending or running")
+
+// ErrJobRunningElsewhere is returned by Cancel when another server instance runs the job.
+var ErrJobRunningElsewhere = errors.New("job is running on another instance")
+
 // JobRepository defines job persistence used by the service.
 type JobRepository interface {
 	CreateJob(ctx context.Context, job *model.CrawlJob) error
 	GetJob(ctx context.Context, id string) (*model.CrawlJob, error)
 	UpdateJobStatus(ctx context.Context, id string, status model.CrawlStatus, errMsg string) error
 	TryIncrementPagesCrawled(ctx context.Context, id string, max int) (bool, error)
+	GetAllJobs(ctx context.Context) ([]*model.CrawlJob, error)
+	UpdateJobHeartbeat(ctx context.Context, id string, instanceID string) error
+	ClaimNextPendingJob(ctx context.Context, instanceID string) (*model.CrawlJob, error)
+	CancelPendingJob(ctx context.Context, id string) (bool, error)
+	GetQueuePosition(ctx context.Context, job *model.CrawlJob) (int, error)
 }
 
 // PageRepository defines page persistence used by the service.
@@ -31,29 +52,84 @@ type PageRepositoryWriter interface {
 }
 
 // CrawlRunner runs a single crawl job. Implemented by the crawl engine.
+// Start must return ctx.Err() when it stops early because ctx was cancelled, and
+// must resume from the job's persisted progress when called again for the same job.
 type CrawlRunner interface {
 	Start(ctx context.Context, job *model.CrawlJob) error
 }
 
+// InputValidator is implemented by runners that can check a job's input up front.
+// Submit rejects input that fails Validate instead of queuing a job bound to fail.
+type InputValidator interface {
+	Validate(input model.CrawlInput) error
+}
+
+// HeartbeatInterval is how often a running job's heartbeat is refreshed. A job whose
+// heartbeat is several intervals old can be treated as orphaned.
+const HeartbeatInterval = 30 * time.Second
+
+// dispatchPollInterval bounds how long the dispatcher sleeps when the queue looks empty,
+// so jobs made PENDING by other instances or the reconciler are still picked up.
+const dispatchPollInterval = 5 * time.Second
+
+// activeJob is the handle kept for a job whose goroutine has been launched.
+// done is closed once the final status has been persisted.
+type activeJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
 // CrawlService orchestrates crawl jobs and the engine.
+// Jobs wait as PENDING rows in the jobs table, which is the durable queue; a dispatcher
+// moves them to RUNNING one at a time while fewer than maxConcurrent jobs are running.
 type CrawlService struct {
-	jobs   JobRepository
-	pages  PageRepository
-	runner CrawlRunner
+	jobs       JobRepository
+	pages      PageRepository
+	runner     CrawlRunner
+	instanceID string // identifies this process as the owner of the jobs it runs
+
+	slots chan struct{} // one token per running job; capacity is the max concurrent jobs
+	wake  chan struct{} // nudges the dispatcher when a job is submitted or finishes
+
+	// mu guards active and serialises claiming a job with Cancel's PENDING check,
+	// so a job cannot be claimed between Cancel looking for its handle and cancelling it.
+	mu     sync.Mutex
+	active map[string]*activeJob // job ID -> handle of its running crawl
 }
 
 // NewCrawlService builds a CrawlService with the given job repo, page repo, and crawl runner.
-func NewCrawlService(jobs JobRepository, pages PageRepository, runner CrawlRunner) *CrawlService {
+// instanceID is recorded as the owner of every job this service runs, and at most
+// maxConcurrent jobs run at once. Jobs are not started until StartDispatcher is called.
+func NewCrawlService(jobs JobRepository, pages PageRepository, runner CrawlRunner, instanceID string, maxConcurrent int) *CrawlService {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
 	return &CrawlService{
-		jobs:   jobs,
-		pages:  pages,
-		runner: runner,
+		jobs:       jobs,
+		pages:      pages,
+		runner:     runner,
+		instanceID: instanceID,
+		slots:      make(chan struct{}, maxConcurrent),
+		wake:       make(chan struct{}, 1),
+		active:     make(map[string]*activeJob),
 	}
 }
 
-// Submit creates a job, stores it, sets status to RUNNING, and starts the crawl in a goroutine.
-// The job is returned immediately; status is updated to COMPLETED or FAILED when the crawl finishes.
+// StartDispatcher starts the goroutine that runs PENDING jobs until ctx is cancelled.
+func (s *CrawlService) StartDispatcher(ctx context.Context) {
+	go s.dispatch(ctx)
+}
+
+// Submit creates a job and stores it as PENDING with its queue position. Input the
+// runner rejects returns an error wrapping ErrInvalidInput.
+// The job is returned immediately; status moves to RUNNING when the dispatcher picks it
+// up and to COMPLETED, CANCELLED or FAILED when it finishes.
 func (s *CrawlService) Submit(ctx context.Context, input model.CrawlInput) (*model.CrawlJob, error) {
+	if v, ok := s.runner.(InputValidator); ok {
+		if err := v.Validate(input); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+	}
 	job := &model.CrawlJob{
 		ID:        uuid.New().String(),
 		Input:     input,
@@ -64,27 +140,185 @@ func (s *CrawlService) Submit(ctx context.Context, input model.CrawlInput) (*mod
 	if err := s.jobs.CreateJob(ctx, job); err != nil {
 		return nil, err
 	}
-	if err := s.jobs.UpdateJobStatus(ctx, job.ID, model.CrawlStatusRunning, ""); err != nil {
+	pos, err := s.jobs.GetQueuePosition(ctx, job)
+	if err != nil {
 		return nil, err
 	}
-	// Run crawl with a background context so it continues after the HTTP response is sent.
+	job.QueuePosition = pos
+	s.notify()
+	return job, nil
+}
+
+// notify wakes the dispatcher without blocking.
+func (s *CrawlService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch waits for a free slot, claims the next PENDING job and launches it, until
+// ctx is cancelled. When the queue is empty it sleeps until notified or polled.
+func (s *CrawlService) dispatch(ctx context.Context) {
+	for {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		job, err := s.jobs.ClaimNextPendingJob(ctx, s.instanceID)
+		if err == nil && job != nil {
+			s.launchLocked(job)
+		}
+		s.mu.Unlock()
+		if err == nil && job != nil {
+			continue
+		}
+
+		<-s.slots // nothing launched; give the slot back
+		if err != nil && ctx.Err() == nil {
+			log.Println("Failed to claim pending job:", err)
+		}
+		select {
+		case <-s.wake:
+		case <-time.After(dispatchPollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// launchLocked starts a claimed job in a goroutine and keeps a handle so Cancel can
+// stop it. The goroutine frees the job's slot when it ends. Must hold s.mu.
+func (s *CrawlService) launchLocked(job *model.CrawlJob) {
+	// Run crawl with a background context so it is independent of any request.
+	crawlCtx, cancel := context.WithCancel(context.Background())
+	aj := &activeJob{cancel: cancel, done: make(chan struct{})}
+	s.active[job.ID] = aj
+
+	go s.run(crawlCtx, job, aj)
+}
+
+// run drives a claimed (already RUNNING) job to its final status, then releases its
+// handle and slot.
+func (s *CrawlService) run(ctx context.Context, job *model.CrawlJob, aj *activeJob) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.active, job.ID)
+		s.mu.Unlock()
+		aj.cancel()
+		close(aj.done)
+		<-s.slots
+		s.notify()
+	}()
+
+	stopHeartbeat := s.heartbeat(ctx, job.ID)
+	err := s.runner.Start(ctx, job)
+	stopHeartbeat()
+	s.finish(job.ID, err)
+}
+
+// heartbeat refreshes the job's heartbeat for this instance every
+// HeartbeatInterval until the returned stop func is called or ctx is cancelled.
+func (s *CrawlService) heartbeat(ctx context.Context, jobID string) (stop func()) {
+	beat := func() {
+		if err := s.jobs.UpdateJobHeartbeat(ctx, jobID, s.instanceID); err != nil && ctx.Err() == nil {
+			log.Println("Failed to update heartbeat for job", jobID, ":", err)
+		}
+	}
+	beat()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
 	go func() {
-		crawlCtx := context.Background()
-		err := s.runner.Start(crawlCtx, job)
-		if err != nil {
-			_ = s.jobs.UpdateJobStatus(crawlCtx, job.ID, model.CrawlStatusFailed, err.Error())
-		} else {
-			_ = s.jobs.UpdateJobStatus(crawlCtx, job.ID, model.CrawlStatusCompleted, "")
+		defer close(stopped)
+		ticker := time.NewTicker(HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				beat()
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
-	return job, nil
+	return func() {
+		close(done)
+		<-stopped
+	}
 }
 
-// GetJob returns a job by ID.
-func (s *CrawlService) GetJob(ctx context.Context, id string) (*model.CrawlJob, error) {
+// finish persists the final status for a job based on the error the crawl ended with.
+// It uses a fresh context because the crawl context may already be cancelled.
+func (s *CrawlService) finish(jobID string, err error) {
+	ctx := context.Background()
+	switch {
+	case errors.Is(err, context.Canceled):
+		_ = s.jobs.UpdateJobStatus(ctx, jobID, model.CrawlStatusCancelled, "")
+	case err != nil:
+		_ = s.jobs.UpdateJobStatus(ctx, jobID, model.CrawlStatusFailed, err.Error())
+	default:
+		_ = s.jobs.UpdateJobStatus(ctx, jobID, model.CrawlStatusCompleted, "")
+	}
+}
+
+// Cancel stops a pending or running job and waits until its CANCELLED status is persisted.
+// Pages already crawled are kept and PagesCrawled reflects the partial count.
+// Returns ErrJobNotActive if the job has already finished, or ErrJobRunningElsewhere
+// if another instance is running it.
+func (s *CrawlService) Cancel(ctx context.Context, id string) (*model.CrawlJob, error) {
+	s.mu.Lock()
+	aj, ok := s.active[id]
+	if !ok {
+		// Holding mu keeps the dispatcher from claiming the job while we cancel it.
+		cancelled, err := s.jobs.CancelPendingJob(ctx, id)
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		job, err := s.jobs.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case cancelled:
+			return job, nil
+		case job.Status == model.CrawlStatusRunning:
+			return nil, ErrJobRunningElsewhere
+		default:
+			return nil, ErrJobNotActive
+		}
+	}
+	s.mu.Unlock()
+
+	aj.cancel()
+	select {
+	case <-aj.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 	return s.jobs.GetJob(ctx, id)
 }
 
+// GetJob returns a job by ID, with its queue position if it is still PENDING.
+func (s *CrawlService) GetJob(ctx context.Context, id string) (*model.CrawlJob, error) {
+	job, err := s.jobs.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == model.CrawlStatusPending {
+		if job.QueuePosition, err = s.jobs.GetQueuePosition(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
 // GetPagesByJobID returns all pages stored for the given job.
 func (s *CrawlService) GetPagesByJobID(ctx context.Context, jobID string) ([]*model.Page, error) {
 	return s.pages.GetPagesByJobID(ctx, jobID)
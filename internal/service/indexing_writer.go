@@ -9,12 +9,16 @@ import (
 type IndexingWriterImpl struct {
 	PageRepositoryWriter PageRepositoryWriter
 	Index                *search.Index
+	// MainContentWeight is how many times a term in a page's main content counts;
+	// 1 indexes it like the rest of the page.
+	MainContentWeight float64
 }
 
 func NewIndexingWriter(pageRepositoryWriter PageRepositoryWriter, index *search.Index) *IndexingWriterImpl {
 	return &IndexingWriterImpl{
 		PageRepositoryWriter: pageRepositoryWriter,
 		Index:                index,
+		MainContentWeight:    search.DefaultMainContentWeight,
 	}
 }
 
@@ -23,9 +27,6 @@ func (i *IndexingWriterImpl) CreatePage(ctx context.Context, page *model.Page) e
 	if err != nil {
 		return err
 	}
-	i.Index.AddDocument(search.Document{
-		ID:   saved.ID,
-		Text: saved.Title + " " + saved.TextContent,
-	})
+	i.Index.AddDocument(search.PageDocument(saved.ID, saved.URL, saved.CanonicalURL, saved.Title, saved.TextContent, saved.MainContent, i.MainContentWeight))
 	return nil
 }
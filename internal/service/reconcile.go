@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-crawler/internal/model"
+)
+
+// OrphanPolicy says what happens to a RUNNING job whose instance stopped.
+type OrphanPolicy string
+
+const (
+	OrphanRequeue OrphanPolicy = "requeue" // put back to PENDING and resume from the frontier
+	OrphanFail    OrphanPolicy = "fail"    // mark FAILED with an explanatory error
+)
+
+// OrphanRepository is the job persistence used by the Reconciler.
+type OrphanRepository interface {
+	GetAllJobs(ctx context.Context) ([]*model.CrawlJob, error)
+	// ReleaseOrphanedJob moves a RUNNING job to status only if, when the update runs,
+	// it has no owner, is owned by ownInstance (empty matches none), or its heartbeat
+	// is older than staleAfter. It reports whether the job was moved.
+	ReleaseOrphanedJob(ctx context.Context, id string, status model.CrawlStatus, errMsg, ownInstance string, staleAfter time.Duration) (bool, error)
+}
+
+// Reconciler finds RUNNING jobs whose instance stopped and requeues or fails them. A
+// job is orphaned once its owner's heartbeat is older than staleAfter, so it keeps
+// checking: a server that restarts under a new instance ID within staleAfter finds
+// its old jobs still fresh, and only a later pass can release them.
+type Reconciler struct {
+	jobs       OrphanRepository
+	instanceID string
+	policy     OrphanPolicy
+	staleAfter time.Duration
+}
+
+// NewReconciler returns a Reconciler for this instance's ID.
+func NewReconciler(jobs OrphanRepository, instanceID string, policy OrphanPolicy, staleAfter time.Duration) *Reconciler {
+	return &Reconciler{jobs: jobs, instanceID: instanceID, policy: policy, staleAfter: staleAfter}
+}
+
+// Reconcile releases the orphaned RUNNING jobs once. At startup, before this instance
+// runs anything, jobs it owns are orphaned too (it restarted with the same
+// INSTANCE_ID); later passes rely on heartbeats only. The database decides each job
+// in a conditional update, so a job another instance has just claimed and is
+// heartbeating is never overwritten. PENDING jobs need nothing: the dispatcher picks
+// them up, and the engine resumes each job from its persisted frontier.
+func (r *Reconciler) Reconcile(ctx context.Context, startup bool) (requeued, failed int, err error) {
+	jobs, err := r.jobs.GetAllJobs(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	ownInstance := ""
+	if startup {
+		ownInstance = r.instanceID
+	}
+	for _, job := range jobs {
+		if job.Status != model.CrawlStatusRunning {
+			continue
+		}
+		status, msg := model.CrawlStatusPending, ""
+		if r.policy == OrphanFail {
+			status = model.CrawlStatusFailed
+			msg = "orphaned: the server running this job stopped before it finished"
+			if job.OwnerInstance != "" {
+				msg = fmt.Sprintf("orphaned: instance %s stopped before the job finished (last heartbeat %s)",
+					job.OwnerInstance, job.HeartbeatAt.Format(time.RFC3339))
+			}
+		}
+		released, err := r.jobs.ReleaseOrphanedJob(ctx, job.ID, status, msg, ownInstance, r.staleAfter)
+		if err != nil {
+			return requeued, failed, err
+		}
+		switch {
+		case !released:
+		case status == model.CrawlStatusFailed:
+			failed++
+		default:
+			requeued++
+		}
+	}
+	return requeued, failed, nil
+}
+
+// Run reconciles every staleAfter/2 until ctx is cancelled, so an orphan is released
+// at most 1.5×staleAfter after its last heartbeat. Requeued jobs wake the dispatcher
+// through its poll interval.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(max(r.staleAfter/2, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			requeued, failed, err := r.Reconcile(ctx, false)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Println("Failed to reconcile jobs:", err)
+				}
+				continue
+			}
+			if requeued > 0 || failed > 0 {
+				log.Printf("Reconciled jobs: %d orphaned requeued, %d orphaned failed", requeued, failed)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
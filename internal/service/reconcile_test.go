@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-crawler/internal/model"
+)
+
+// fakeOrphanRepo keeps jobs in memory and applies ReleaseOrphanedJob's condition the
+// way the SQL does, against the row as it is when the update runs.
+type fakeOrphanRepo struct {
+	mu   sync.Mutex
+	jobs map[string]*model.CrawlJob
+	// beforeRelease, if set, runs just before each update, e.g. to let another
+	// instance claim the job between the listing and the update.
+	beforeRelease func(id string)
+}
+
+func (f *fakeOrphanRepo) GetAllJobs(ctx context.Context) ([]*model.CrawlJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*model.CrawlJob
+	for _, j := range f.jobs {
+		cp := *j
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (f *fakeOrphanRepo) ReleaseOrphanedJob(ctx context.Context, id string, status model.CrawlStatus, errMsg, ownInstance string, staleAfter time.Duration) (bool, error) {
+	if f.beforeRelease != nil {
+		f.beforeRelease(id)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	j := f.jobs[id]
+	if j == nil || j.Status != model.CrawlStatusRunning {
+		return false, nil
+	}
+	if j.OwnerInstance != "" && (ownInstance == "" || j.OwnerInstance != ownInstance) &&
+		!j.HeartbeatAt.IsZero() && time.Since(j.HeartbeatAt) <= staleAfter {
+		return false, nil
+	}
+	j.Status, j.Error = status, errMsg
+	return true, nil
+}
+
+func (f *fakeOrphanRepo) job(id string) model.CrawlJob {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return *f.jobs[id]
+}
+
+func running(id, owner string, heartbeat time.Time) *model.CrawlJob {
+	return &model.CrawlJob{ID: id, Status: model.CrawlStatusRunning, OwnerInstance: owner, HeartbeatAt: heartbeat}
+}
+
+func TestReconcileStartup(t *testing.T) {
+	now := time.Now()
+	repo := &fakeOrphanRepo{jobs: map[string]*model.CrawlJob{
+		"own":      running("own", "me", now),
+		"unowned":  running("unowned", "", time.Time{}),
+		"stale":    running("stale", "other", now.Add(-time.Hour)),
+		"live":     running("live", "other", now),
+		"pending":  {ID: "pending", Status: model.CrawlStatusPending},
+		"finished": {ID: "finished", Status: model.CrawlStatusCompleted},
+	}}
+	r := NewReconciler(repo, "me", OrphanRequeue, time.Minute)
+	requeued, failed, err := r.Reconcile(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requeued != 3 || failed != 0 {
+		t.Errorf("requeued, failed = %d, %d, want 3, 0", requeued, failed)
+	}
+	want := map[string]model.CrawlStatus{
+		"own":      model.CrawlStatusPending,
+		"unowned":  model.CrawlStatusPending,
+		"stale":    model.CrawlStatusPending,
+		"live":     model.CrawlStatusRunning,
+		"pending":  model.CrawlStatusPending,
+		"finished": model.CrawlStatusCompleted,
+	}
+	for id, status := range want {
+		if got := repo.job(id).Status; got != status {
+			t.Errorf("%s: status %s, want %s", id, got, status)
+		}
+	}
+}
+
+func TestReconcileFailPolicy(t *testing.T) {
+	repo := &fakeOrphanRepo{jobs: map[string]*model.CrawlJob{
+		"stale": running("stale", "other", time.Now().Add(-time.Hour)),
+	}}
+	r := NewReconciler(repo, "me", OrphanFail, time.Minute)
+	if _, failed, err := r.Reconcile(context.Background(), false); err != nil || failed != 1 {
+		t.Fatalf("failed = %d, err = %v, want 1, nil", failed, err)
+	}
+	job := repo.job("stale")
+	if job.Status != model.CrawlStatusFailed || !strings.Contains(job.Error, "instance other stopped") {
+		t.Errorf("job = %s %q, want FAILED with an orphan message", job.Status, job.Error)
+	}
+}
+
+// A job that looked stale when listed but was claimed by another instance before the
+// update must be left to that instance.
+func TestReconcileKeepsReclaimedJob(t *testing.T) {
+	repo := &fakeOrphanRepo{jobs: map[string]*model.CrawlJob{
+		"job": running("job", "old", time.Now().Add(-time.Hour)),
+	}}
+	repo.beforeRelease = func(id string) {
+		repo.mu.Lock()
+		repo.jobs[id].OwnerInstance = "other"
+		repo.jobs[id].HeartbeatAt = time.Now()
+		repo.mu.Unlock()
+	}
+	r := NewReconciler(repo, "me", OrphanRequeue, time.Minute)
+	if requeued, _, err := r.Reconcile(context.Background(), false); err != nil || requeued != 0 {
+		t.Fatalf("requeued = %d, err = %v, want 0, nil", requeued, err)
+	}
+	if job := repo.job("job"); job.Status != model.CrawlStatusRunning || job.OwnerInstance != "other" {
+		t.Errorf("job = %s owned by %q, want RUNNING owned by other", job.Status, job.OwnerInstance)
+	}
+}
+
+// A server that crashed and restarted under a new instance ID within staleAfter sees
+// its old job's heartbeat as fresh at startup; the periodic pass must release it once
+// the heartbeat goes stale.
+func TestReconcileRestartedWithinWindow(t *testing.T) {
+	const staleAfter = 100 * time.Millisecond
+	repo := &fakeOrphanRepo{jobs: map[string]*model.CrawlJob{
+		"job": running("job", "old", time.Now()),
+	}}
+	r := NewReconciler(repo, "new", OrphanRequeue, staleAfter)
+	if requeued, _, err := r.Reconcile(context.Background(), true); err != nil || requeued != 0 {
+		t.Fatalf("startup: requeued = %d, err = %v, want 0, nil", requeued, err)
+	}
+	if got := repo.job("job").Status; got != model.CrawlStatusRunning {
+		t.Fatalf("after startup: status %s, want RUNNING", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+	deadline := time.Now().Add(2 * time.Second)
+	for repo.job("job").Status != model.CrawlStatusPending {
+		if time.Now().After(deadline) {
+			t.Fatal("orphaned job was not requeued by the periodic pass")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
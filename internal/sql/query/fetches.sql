@@ -0,0 +1,13 @@
+-- name: CreateFetch :exec
+INSERT INTO fetches (job_id, url, attempt, status_code, latency_ms, bytes, content_type, error_class, error, method)
+VALUES (sqlc.arg(job_id), sqlc.arg(url), sqlc.arg(attempt), sqlc.arg(status_code), sqlc.arg(latency_ms), sqlc.arg(bytes), sqlc.arg(content_type), sqlc.arg(error_class), sqlc.arg(error), sqlc.arg(method));
+
+-- name: ListFetchesByJobID :many
+SELECT * FROM fetches
+WHERE job_id = sqlc.arg(job_id)
+  AND (sqlc.narg(url)::text IS NULL OR strpos(url, sqlc.narg(url)) > 0)
+  AND (sqlc.narg(status_code)::int IS NULL OR status_code = sqlc.narg(status_code))
+  AND (sqlc.narg(error_class)::text IS NULL OR error_class = sqlc.narg(error_class))
+  AND (NOT sqlc.arg(failed_only)::bool OR error_class = ANY(sqlc.arg(failure_classes)::text[]))
+ORDER BY id
+LIMIT sqlc.arg(row_limit);
@@ -0,0 +1,15 @@
+-- name: AddFrontierURL :exec
+INSERT INTO frontier (job_id, url, depth, state, discovered_from)
+VALUES (sqlc.arg(job_id), sqlc.arg(url), sqlc.arg(depth), sqlc.arg(state), sqlc.arg(discovered_from))
+ON CONFLICT (job_id, url) DO NOTHING;
+
+-- name: UpdateFrontierState :exec
+UPDATE frontier SET state = sqlc.arg(state), updated_at = NOW()
+WHERE job_id = sqlc.arg(job_id) AND url = sqlc.arg(url);
+
+-- name: RetryFrontierURL :exec
+UPDATE frontier SET state = 'QUEUED', attempts = sqlc.arg(attempts), next_attempt_at = sqlc.arg(next_attempt_at), updated_at = NOW()
+WHERE job_id = sqlc.arg(job_id) AND url = sqlc.arg(url);
+
+-- name: GetFrontierByJobID :many
+SELECT * FROM frontier WHERE job_id = sqlc.arg(job_id) ORDER BY id;
@@ -2,15 +2,41 @@
 SELECT * FROM jobs WHERE id = sqlc.arg(id);
 
 -- name: CreateJob :one
-INSERT INTO jobs (id, input, status, error, pages_crawled, created_at, updated_at)
-VALUES (sqlc.arg(id), sqlc.arg(input), sqlc.arg(status), sqlc.arg(error), sqlc.arg(pages_crawled), sqlc.arg(created_at), sqlc.arg(updated_at)) RETURNING *;
+INSERT INTO jobs (id, input, status, error, pages_crawled, priority, created_at, updated_at)
+VALUES (sqlc.arg(id), sqlc.arg(input), sqlc.arg(status), sqlc.arg(error), sqlc.arg(pages_crawled), sqlc.arg(priority), sqlc.arg(created_at), sqlc.arg(updated_at)) RETURNING *;
 
 -- name: UpdateJobStatus :one
 UPDATE jobs SET status = sqlc.arg(status), error = sqlc.arg(error), updated_at = NOW() WHERE id = sqlc.arg(id) RETURNING *;
 
+-- name: ReleaseOrphanedJob :execrows
+UPDATE jobs SET status = sqlc.arg(status), error = sqlc.arg(error), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND status = 'RUNNING'
+  AND (owner_instance IS NULL OR owner_instance = sqlc.narg(own_instance)
+    OR heartbeat_at IS NULL OR heartbeat_at < NOW() - sqlc.arg(stale_after)::interval);
+
 -- name: TryIncrementPagesCrawled :one
 UPDATE jobs SET pages_crawled = pages_crawled + 1, updated_at = NOW()
 WHERE id = sqlc.arg(id) AND pages_crawled < sqlc.arg(max_pages) RETURNING *;
 
 -- name: GetAllJobs :many
-SELECT * FROM jobs;
\ No newline at end of file
+SELECT * FROM jobs;
+
+-- name: UpdateJobHeartbeat :exec
+UPDATE jobs SET owner_instance = sqlc.arg(owner_instance), heartbeat_at = NOW() WHERE id = sqlc.arg(id);
+
+-- name: ClaimNextPendingJob :one
+UPDATE jobs SET status = 'RUNNING', owner_instance = sqlc.arg(owner_instance), heartbeat_at = NOW(), updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs WHERE status = 'PENDING'
+    ORDER BY priority DESC, created_at, id
+    LIMIT 1 FOR UPDATE SKIP LOCKED
+) RETURNING *;
+
+-- name: CancelPendingJob :execrows
+UPDATE jobs SET status = 'CANCELLED', updated_at = NOW() WHERE id = sqlc.arg(id) AND status = 'PENDING';
+
+-- name: CountPendingJobsAhead :one
+SELECT COUNT(*) FROM jobs
+WHERE status = 'PENDING'
+  AND (priority > sqlc.arg(priority)
+    OR (priority = sqlc.arg(priority) AND (created_at, id) < (sqlc.arg(created_at)::timestamptz, sqlc.arg(id)::uuid)));
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: CreateLink :exec
+INSERT INTO links (job_id, source_url, target_url, anchor_text, rel, element, depth)
+VALUES (sqlc.arg(job_id), sqlc.arg(source_url), sqlc.arg(target_url), sqlc.arg(anchor_text), sqlc.arg(rel), sqlc.arg(element), sqlc.arg(depth))
+ON CONFLICT (job_id, source_url, target_url) DO NOTHING;
+
+-- name: ListOutboundLinks :many
+SELECT * FROM links WHERE job_id = sqlc.arg(job_id) AND source_url = sqlc.arg(source_url) ORDER BY id;
+
+-- name: ListInboundLinks :many
+SELECT * FROM links WHERE job_id = sqlc.arg(job_id) AND target_url = sqlc.arg(target_url) ORDER BY id;
+
+-- name: GetCrawlTree :many
+SELECT f.url, f.depth, f.discovered_from, f.state, l.anchor_text
+FROM frontier f
+LEFT JOIN links l ON l.job_id = f.job_id AND l.source_url = f.discovered_from AND l.target_url = f.url
+WHERE f.job_id = sqlc.arg(job_id)
+ORDER BY f.depth, f.id;
+
+-- name: GetCrawlPath :many
+WITH RECURSIVE path AS (
+    SELECT url, depth, discovered_from, state FROM frontier WHERE job_id = sqlc.arg(job_id) AND url = sqlc.arg(url)
+    UNION
+    SELECT f.url, f.depth, f.discovered_from, f.state FROM frontier f JOIN path p ON f.url = p.discovered_from
+    WHERE f.job_id = sqlc.arg(job_id)
+)
+SELECT p.url, p.depth, p.discovered_from, p.state, l.anchor_text
+FROM path p
+LEFT JOIN links l ON l.job_id = sqlc.arg(job_id) AND l.source_url = p.discovered_from AND l.target_url = p.url
+ORDER BY p.depth;
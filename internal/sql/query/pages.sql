@@ -1,11 +1,26 @@
 -- name: UpsertPage :one
-INSERT INTO pages (job_id, url, title, html, text_content)
-VALUES (sqlc.arg(job_id), sqlc.arg(url), sqlc.arg(title), sqlc.arg(html), sqlc.arg(text_content))
+INSERT INTO pages (job_id, url, title, html, text_content, main_content, stats, status_code, final_url, content_type, charset, headers, content_length, fetch_duration_ms, content_hash, redirect_chain, canonical_url, metadata, extracted, tables)
+VALUES (sqlc.arg(job_id), sqlc.arg(url), sqlc.arg(title), sqlc.arg(html), sqlc.arg(text_content), sqlc.arg(main_content), sqlc.arg(stats), sqlc.arg(status_code), sqlc.arg(final_url), sqlc.arg(content_type), sqlc.arg(charset), sqlc.arg(headers), sqlc.arg(content_length), sqlc.arg(fetch_duration_ms), sqlc.arg(content_hash), sqlc.arg(redirect_chain), sqlc.arg(canonical_url), sqlc.arg(metadata), sqlc.arg(extracted), sqlc.arg(tables))
 ON CONFLICT (url) DO UPDATE SET
 job_id = EXCLUDED.job_id,
 title = EXCLUDED.title,
 html = EXCLUDED.html,
 text_content = EXCLUDED.text_content,
+main_content = EXCLUDED.main_content,
+stats = EXCLUDED.stats,
+status_code = EXCLUDED.status_code,
+final_url = EXCLUDED.final_url,
+content_type = EXCLUDED.content_type,
+charset = EXCLUDED.charset,
+headers = EXCLUDED.headers,
+content_length = EXCLUDED.content_length,
+fetch_duration_ms = EXCLUDED.fetch_duration_ms,
+content_hash = EXCLUDED.content_hash,
+redirect_chain = EXCLUDED.redirect_chain,
+canonical_url = EXCLUDED.canonical_url,
+metadata = EXCLUDED.metadata,
+extracted = EXCLUDED.extracted,
+tables = EXCLUDED.tables,
 fetched_at = NOW()
 RETURNING *;
 
@@ -13,4 +28,10 @@ RETURNING *;
 SELECT * FROM pages WHERE job_id = sqlc.arg(job_id);
 
 -- name: ListPagesForIndex :many
-SELECT id,title, text_content FROM pages;
\ No newline at end of file
+SELECT id,title, text_content, main_content, url, canonical_url FROM pages;
+
+-- name: ListExtractedByJobID :many
+SELECT id, url, extracted FROM pages WHERE job_id = sqlc.arg(job_id) AND extracted IS NOT NULL ORDER BY id;
+
+-- name: ListTablesByJobID :many
+SELECT id, url, tables FROM pages WHERE job_id = sqlc.arg(job_id) AND tables IS NOT NULL ORDER BY id;
@@ -0,0 +1,6 @@
+-- name: CreateSkippedURL :exec
+INSERT INTO skipped_urls (job_id, url, reason)
+VALUES (sqlc.arg(job_id), sqlc.arg(url), sqlc.arg(reason));
+
+-- name: GetSkippedURLsByJobID :many
+SELECT * FROM skipped_urls WHERE job_id = sqlc.arg(job_id) ORDER BY id;
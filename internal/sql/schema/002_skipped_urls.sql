@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS skipped_urls (
+    id SERIAL PRIMARY KEY,
+    job_id UUID REFERENCES jobs(id),
+    url TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    skipped_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS skipped_urls_job_id_idx ON skipped_urls (job_id);
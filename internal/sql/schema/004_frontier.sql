@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS frontier (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id),
+    url TEXT NOT NULL,
+    depth INT NOT NULL,
+    state TEXT NOT NULL,
+    discovered_from TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (job_id, url)
+);
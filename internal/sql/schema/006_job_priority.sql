@@ -0,0 +1,3 @@
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS jobs_pending_queue_idx ON jobs (priority DESC, created_at, id) WHERE status = 'PENDING';
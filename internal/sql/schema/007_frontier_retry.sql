@@ -0,0 +1,2 @@
+ALTER TABLE frontier ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE frontier ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS fetches (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id),
+    url TEXT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT NOT NULL,
+    latency_ms INT NOT NULL,
+    bytes BIGINT NOT NULL,
+    content_type TEXT NOT NULL,
+    error_class TEXT NOT NULL,
+    error TEXT NOT NULL,
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS fetches_job_id_idx ON fetches (job_id, id);
@@ -0,0 +1,8 @@
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS status_code INT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS final_url TEXT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS content_type TEXT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS charset TEXT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS headers JSONB;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS content_length BIGINT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS fetch_duration_ms INT;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS content_hash TEXT;
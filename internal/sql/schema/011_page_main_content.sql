@@ -0,0 +1 @@
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS main_content TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS canonical_url TEXT;
+
+CREATE INDEX IF NOT EXISTS pages_canonical_url_idx ON pages (canonical_url);
@@ -0,0 +1 @@
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS tables JSONB;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS links (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs(id),
+    source_url TEXT NOT NULL,
+    target_url TEXT NOT NULL,
+    anchor_text TEXT NOT NULL,
+    rel TEXT NOT NULL,
+    element TEXT NOT NULL,
+    depth INT NOT NULL,
+    discovered_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (job_id, source_url, target_url)
+);
+
+CREATE INDEX IF NOT EXISTS links_target_idx ON links (job_id, target_url);
@@ -0,0 +1 @@
+ALTER TABLE fetches ADD COLUMN IF NOT EXISTS method TEXT NOT NULL DEFAULT 'GET';
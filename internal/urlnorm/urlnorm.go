@@ -0,0 +1,195 @@
+package urlnorm
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// DefaultTrackingParams are query parameters removed by default. A trailing "*"
+// matches any parameter with that prefix.
+var DefaultTrackingParams = []string{
+	"utm_*",
+	"gclid",
+	"dclid",
+	"fbclid",
+	"msclkid",
+	"yclid",
+	"mc_cid",
+	"mc_eid",
+	"igshid",
+	"_ga",
+	"_hsenc",
+	"_hsmi",
+}
+
+// Normalizer canonicalizes URLs so trivially different spellings of one page (case,
+// default ports, fragments, dot segments, query order, tracking parameters, trailing
+// slashes) map to one string. It is immutable and safe for concurrent use.
+type Normalizer struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+// Default strips DefaultTrackingParams.
+var Default = New(DefaultTrackingParams)
+
+// New returns a Normalizer that strips the given query parameters (matched
+// case-insensitively; a trailing "*" matches a prefix).
+func New(stripParams []string) *Normalizer {
+	n := &Normalizer{exact: make(map[string]bool)}
+	for _, p := range stripParams {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			n.prefixes = append(n.prefixes, prefix)
+			continue
+		}
+		n.exact[p] = true
+	}
+	return n
+}
+
+// With returns a Normalizer that strips n's parameters plus extra.
+func (n *Normalizer) With(extra []string) *Normalizer {
+	if len(extra) == 0 {
+		return n
+	}
+	params := make([]string, 0, len(n.exact)+len(n.prefixes)+len(extra))
+	for p := range n.exact {
+		params = append(params, p)
+	}
+	for _, p := range n.prefixes {
+		params = append(params, p+"*")
+	}
+	return New(append(params, extra...))
+}
+
+// Normalize returns the canonical form of raw using Default.
+func Normalize(raw string) (string, error) {
+	return Default.Normalize(raw)
+}
+
+// Normalize returns the canonical form of raw:
+//   - scheme and host lowercased, default port (80/443) removed
+//   - fragment removed
+//   - dot segments resolved, empty path becomes "/", trailing slash removed otherwise
+//   - configured parameters removed and remaining query parameters sorted by key
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Opaque != "" {
+		return u.String(), nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !isDefaultPort(u.Scheme, port) {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6 literal without port
+	}
+	u.Host = host
+
+	if err := setPath(u, normalizePath(u.EscapedPath())); err != nil {
+		return "", err
+	}
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
+
+// normalizePath resolves "." and ".." segments (RFC 3986 section 5.2.4) in an
+// escaped path and drops a trailing slash, except for the root.
+func normalizePath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	segments := strings.Split(p, "/")
+	out := make([]string, 0, len(segments))
+	for i, seg := range segments {
+		switch seg {
+		case ".":
+		case "..":
+			if len(out) > 1 {
+				out = out[:len(out)-1]
+			}
+		default:
+			if seg == "" && i != 0 && i == len(segments)-1 {
+				continue // trailing slash
+			}
+			out = append(out, seg)
+		}
+	}
+	result := strings.Join(out, "/")
+	if !strings.HasPrefix(result, "/") {
+		result = "/" + result
+	}
+	return result
+}
+
+// setPath sets u's path from an escaped path, keeping the original escaping.
+func setPath(u *url.URL, escaped string) error {
+	unescaped, err := url.PathUnescape(escaped)
+	if err != nil {
+		return err
+	}
+	u.Path = unescaped
+	u.RawPath = escaped
+	return nil
+}
+
+// normalizeQuery removes stripped parameters and empty pairs and sorts the rest by key,
+// keeping the order of repeated keys. Each pair keeps its original spelling, so a bare
+// key ("?x") is not turned into an empty value ("?x=") and escaping is not changed:
+// the normalized URL is the one requested.
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	type pair struct{ key, raw string }
+	var pairs []pair
+	for _, raw := range strings.Split(rawQuery, "&") {
+		if raw == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(raw, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if n.strips(key) {
+			continue
+		}
+		pairs = append(pairs, pair{key: key, raw: raw})
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int { return strings.Compare(a.key, b.key) })
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p.raw
+	}
+	return strings.Join(parts, "&")
+}
+
+func (n *Normalizer) strips(key string) bool {
+	key = strings.ToLower(key)
+	if n.exact[key] {
+		return true
+	}
+	for _, prefix := range n.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
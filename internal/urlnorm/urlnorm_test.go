@@ -0,0 +1,80 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"scheme and host case", "HTTP://Example.COM/Path", "http://example.com/Path"},
+		{"surrounding space", "  http://example.com/a  ", "http://example.com/a"},
+		{"default http port", "http://example.com:80/a", "http://example.com/a"},
+		{"default https port", "https://example.com:443/a", "https://example.com/a"},
+		{"other port kept", "http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https on port 80 kept", "https://example.com:80/a", "https://example.com:80/a"},
+		{"ipv6 literal", "http://[::1]:80/a", "http://[::1]/a"},
+		{"ipv6 with port", "http://[::1]:8080/a", "http://[::1]:8080/a"},
+		{"fragment", "http://example.com/a#top", "http://example.com/a"},
+		{"empty path", "http://example.com", "http://example.com/"},
+		{"root slash kept", "http://example.com/", "http://example.com/"},
+		{"trailing slash", "http://example.com/a/b/", "http://example.com/a/b"},
+		{"dot segments", "http://example.com/a/./b/../c", "http://example.com/a/c"},
+		{"dot segments above root", "http://example.com/../../a", "http://example.com/a"},
+		{"escaping kept", "http://example.com/a%2Fb/c%20d", "http://example.com/a%2Fb/c%20d"},
+		{"query sorted", "http://example.com/?b=2&a=1&c=3", "http://example.com/?a=1&b=2&c=3"},
+		{"repeated keys keep order", "http://example.com/?b=1&a=2&b=0", "http://example.com/?a=2&b=1&b=0"},
+		{"bare key kept", "http://example.com/?x", "http://example.com/?x"},
+		{"bare and empty value differ", "http://example.com/?y=&x", "http://example.com/?x&y="},
+		{"query escaping kept", "http://example.com/?q=a%20b&p=c+d", "http://example.com/?p=c+d&q=a%20b"},
+		{"empty pairs dropped", "http://example.com/?&a=1&&", "http://example.com/?a=1"},
+		{"empty query dropped", "http://example.com/a?", "http://example.com/a"},
+		{"tracking params", "http://example.com/?utm_source=x&id=7&UTM_Medium=y&gclid=z", "http://example.com/?id=7"},
+		{"only tracking params", "http://example.com/a?fbclid=1", "http://example.com/a"},
+		{"escaped tracking key", "http://example.com/?utm%5Fsource=x&id=7", "http://example.com/?id=7"},
+		{"opaque", "mailto:Someone@Example.com", "mailto:Someone@Example.com"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if err != nil {
+			t.Errorf("%s: Normalize(%q) error: %v", tt.name, tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Normalize(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	for _, in := range []string{"http://exa mple.com/", "http://example.com/%zz", ":nope"} {
+		if got, err := Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) = %q, want an error", in, got)
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	n := Default.With([]string{"sessionid", "ref_*"})
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"http://example.com/?sessionid=1&a=1", "http://example.com/?a=1"},
+		{"http://example.com/?ref_src=x&ref=y", "http://example.com/?ref=y"},
+		{"http://example.com/?utm_source=x&a=1", "http://example.com/?a=1"},
+	}
+	for _, tt := range tests {
+		got, err := n.Normalize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+	if got, _ := Default.Normalize("http://example.com/?sessionid=1"); got != "http://example.com/?sessionid=1" {
+		t.Errorf("With changed Default: got %q", got)
+	}
+	if Default.With(nil) != Default {
+		t.Error("With(nil) should return the receiver")
+	}
+}